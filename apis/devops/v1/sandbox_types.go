@@ -18,6 +18,7 @@ package v1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,25 +35,304 @@ type SandboxSpec struct {
 	// The SlackID of the User, used to notify the user of cleanups
 	SlackId string `json:"slack_id"`
 
-	// The ExpirationDate for this sandbox, if not given, the reaper will use a default TTL
+	// The ExpirationDate for this sandbox, if not given, it is defaulted from the default TTL on creation
 	ExpirationDate *metav1.Time `json:"expiration_date,omitempty"`
 
-	// ManualExpiry will prevent this sandbox from being reaped if no ExpirationDate is given.
+	// ManualExpiry will prevent this sandbox from being reaped if no ExpirationDate is given. It is not allowed when
+	// the operator limits the lifetime of sandboxes.
 	ManualExpiry bool `json:"manual_expiry,omitempty" default:"false"`
+
+	// DisableIdleReaping prevents this sandbox from being reaped when there is no activity in it.
+	// +optional
+	DisableIdleReaping bool `json:"disable_idle_reaping,omitempty"`
+
+	// Warnings determines when the User is warned that this sandbox is about to expire, if not given, the
+	// operator-wide warning settings are used
+	// +optional
+	Warnings *WarningPolicy `json:"warnings,omitempty"`
+
+	// TemplateRef refers to the SandboxTemplate that is used to provision this sandbox
+	// +optional
+	TemplateRef *SandboxTemplateReference `json:"template_ref,omitempty"`
+
+	// Groups are granted the same permissions in the sandbox as the User, the requester has to be a member of them
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Isolation determines which network traffic is allowed to and from the sandbox, if not given, the
+	// operator-wide default is used
+	// +optional
+	Isolation IsolationMode `json:"isolation,omitempty"`
+
+	// Secrets are copied into the sandbox and kept in sync with their source, in addition to the operator-wide
+	// shared secrets. Only the secrets allowed by the operator can be requested, and their names must be unique.
+	// Secrets of type kubernetes.io/dockerconfigjson are added as imagePullSecrets to the default ServiceAccount.
+	// +optional
+	Secrets []v1.SecretReference `json:"secrets,omitempty"`
+
+	// PreDeleteHooks are run as Jobs in the sandbox namespace before it is deleted
+	// +optional
+	PreDeleteHooks []SandboxHook `json:"pre_delete_hooks,omitempty"`
+
+	// Resources is the resource budget of this sandbox, if not given, the operator-wide defaults are used
+	// +optional
+	Resources *SandboxResources `json:"resources,omitempty"`
+
+	// Namespaces are the suffixes of the namespaces that are provisioned for this sandbox, e.g. app and data. Every
+	// namespace gets the full resource budget. If not given, a single namespace is provisioned. Namespaces can only be
+	// removed or renamed when the sandbox is annotated with sandboxer/prune-namespaces=true, as they are deleted.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Hibernate scales the Deployments and StatefulSets in the sandbox to zero and suspends its CronJobs. They are
+	// restored to their original state once it is cleared.
+	// +optional
+	Hibernate bool `json:"hibernate,omitempty"`
+
+	// Uptime is the schedule in which the workloads in the sandbox run, outside of it the sandbox is hibernated. If
+	// not given, the sandbox is up unless it is hibernated.
+	// +optional
+	Uptime *UptimeSchedule `json:"uptime,omitempty"`
+}
+
+// UptimeSchedule is a recurring window in which the workloads of a Sandbox run. Outside the window, the sandbox is
+// hibernated.
+type UptimeSchedule struct {
+	// Days are the days of the week on which the window starts, as a comma-separated list of day names or ranges of
+	// day names, e.g. Mon-Fri or Mon,Wed,Fri. If not given, the window starts every day.
+	// +optional
+	Days string `json:"days,omitempty"`
+	// Start is the time of day at which the window starts, e.g. 07:00
+	Start string `json:"start"`
+	// End is the time of day at which the window ends, e.g. 19:00. If it is before the start, the window ends on the
+	// next day.
+	End string `json:"end"`
+	// TimeZone is the IANA name of the time zone of the start and end times, e.g. Europe/Amsterdam, defaults to UTC
+	// +optional
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// WarningPolicy overrides the operator-wide settings for the expiration warnings of a Sandbox. Fields that are not
+// given fall back to the operator-wide settings.
+type WarningPolicy struct {
+	// FirstWarning is how long before the expiration date the first warning is sent, e.g. 72h
+	// +optional
+	FirstWarning *metav1.Duration `json:"first_warning,omitempty"`
+	// Interval is the time between consecutive warnings, e.g. 24h
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Mute disables the expiration and idle warnings for this sandbox, it is still reaped when it expires
+	// +optional
+	Mute bool `json:"mute,omitempty"`
+}
+
+// SandboxHook is a container that is run as a Job in the sandbox namespace
+type SandboxHook struct {
+	// Name of the hook, must be unique within the Sandbox and a DNS label of at most 44 characters
+	Name string `json:"name"`
+	// Image of the container that is run
+	Image string `json:"image"`
+	// Command that is run in the container, if not given the entrypoint of the image is used
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args that are passed to the command
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Timeout after which the hook is abandoned, defaults to 5 minutes
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// IsolationMode describes how the network of a Sandbox is isolated from the rest of the cluster.
+// +kubebuilder:validation:Enum=isolated;shared;open
+type IsolationMode string
+
+const (
+	// IsolationIsolated only allows traffic within the sandbox namespace and to DNS.
+	IsolationIsolated IsolationMode = "isolated"
+	// IsolationShared additionally allows traffic to and from the shared-service namespaces.
+	IsolationShared IsolationMode = "shared"
+	// IsolationOpen does not restrict any traffic.
+	IsolationOpen IsolationMode = "open"
+)
+
+// SandboxResources defines the resource budget of a Sandbox. Any field that is left empty
+// falls back to the operator-wide default.
+type SandboxResources struct {
+	// CPU is the total amount of CPU that can be requested and used by all pods in the sandbox
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// Memory is the total amount of memory that can be requested and used by all pods in the sandbox
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// Storage is the total amount of storage that can be claimed by persistent volume claims in the sandbox
+	// +optional
+	Storage *resource.Quantity `json:"storage,omitempty"`
+
+	// Pods is the maximum number of pods in the sandbox
+	// +optional
+	Pods *int64 `json:"pods,omitempty"`
+	// Services is the maximum number of services in the sandbox
+	// +optional
+	Services *int64 `json:"services,omitempty"`
+	// PersistentVolumeClaims is the maximum number of persistent volume claims in the sandbox
+	// +optional
+	PersistentVolumeClaims *int64 `json:"persistent_volume_claims,omitempty"`
+
+	// DefaultRequest are the resource requests given to containers that do not specify any
+	// +optional
+	DefaultRequest v1.ResourceList `json:"default_request,omitempty"`
+	// DefaultLimit are the resource limits given to containers that do not specify any
+	// +optional
+	DefaultLimit v1.ResourceList `json:"default_limit,omitempty"`
 }
 
 // SandboxStatus defines the observed state of Sandbox
 type SandboxStatus struct {
-	NamespaceStatus  v1.NamespaceStatus `json:",omitempty"`
+	// NamespaceStatus is a copy of the status of the first sandbox namespace
+	NamespaceStatus  v1.NamespaceStatus `json:"NamespaceStatus,omitempty"`
 	LastNotification *metav1.Time       `json:"last_notification,omitempty"`
 
+	// Phase summarizes the lifecycle state of the sandbox
+	// +optional
+	Phase SandboxPhase `json:"phase,omitempty"`
+	// ObservedGeneration is the most recent generation of the Sandbox that was reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+	// Namespace is the name of the first namespace that is provisioned for the sandbox
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Namespaces reports the state of every namespace that is provisioned for the sandbox
+	// +optional
+	Namespaces []SandboxNamespaceStatus `json:"namespaces,omitempty"`
+	// ExpirationDate is the effective date on which the sandbox expires, either given in the spec or
+	// derived from the default TTL
+	// +optional
+	ExpirationDate *metav1.Time `json:"expiration_date,omitempty"`
+	// LastActivity is the last time a pod was created or restarted, or a workload was rolled out in the sandbox
+	// +optional
+	LastActivity *metav1.Time `json:"last_activity,omitempty"`
+	// Conditions are the latest observations of the state of the sandbox
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Teardown reports the progress of deleting the sandbox
+	// +optional
+	Teardown *SandboxTeardownStatus `json:"teardown,omitempty"`
+	// Usage reports the resources that are requested and used in the sandbox, and what they cost
+	// +optional
+	Usage *SandboxUsage `json:"usage,omitempty"`
+
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 }
 
+// SandboxNamespaceStatus reports the state of a namespace of a Sandbox
+type SandboxNamespaceStatus struct {
+	// Name of the namespace
+	Name string `json:"name"`
+	// Phase of the namespace
+	// +optional
+	Phase v1.NamespacePhase `json:"phase,omitempty"`
+}
+
+// SandboxUsage reports the resources that are requested and used in the namespaces of a Sandbox, and what they cost
+type SandboxUsage struct {
+	// Requests are the total resource requests of the pods and persistent volume claims in the sandbox
+	// +optional
+	Requests v1.ResourceList `json:"requests,omitempty"`
+	// Used is the total resource usage of the pods in the sandbox, if the metrics API is available
+	// +optional
+	Used v1.ResourceList `json:"used,omitempty"`
+	// HourlyCost is the cost of the sandbox per hour, based on the larger of the requested and used amount of every
+	// resource
+	// +optional
+	HourlyCost string `json:"hourly_cost,omitempty"`
+	// Currency of the HourlyCost
+	// +optional
+	Currency string `json:"currency,omitempty"`
+	// ObservedAt is the last time the usage was aggregated
+	// +optional
+	ObservedAt *metav1.Time `json:"observed_at,omitempty"`
+}
+
+// SandboxTeardownStatus reports the progress of deleting a Sandbox
+type SandboxTeardownStatus struct {
+	// Step is the teardown step that is currently executed
+	Step TeardownStep `json:"step"`
+	// BlockingFinalizers are the finalizers that prevent the sandbox namespace from being removed
+	// +optional
+	BlockingFinalizers []string `json:"blocking_finalizers,omitempty"`
+	// Message explains what the teardown is waiting for
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TeardownStep is a step in the ordered teardown of a Sandbox
+type TeardownStep string
+
+const (
+	// TeardownPreDeleteHooks waits for the pre-delete hooks to finish.
+	TeardownPreDeleteHooks TeardownStep = "PreDeleteHooks"
+	// TeardownDeletingNamespace waits for the sandbox namespace to be removed.
+	TeardownDeletingNamespace TeardownStep = "DeletingNamespace"
+	// TeardownCleaningUp removes the cluster-scoped leftovers of the sandbox.
+	TeardownCleaningUp TeardownStep = "CleaningUp"
+)
+
+// SandboxPhase is a label for the lifecycle state of a Sandbox
+type SandboxPhase string
+
+const (
+	// SandboxPending means the sandbox namespace is not yet fully provisioned.
+	SandboxPending SandboxPhase = "Pending"
+	// SandboxReady means the sandbox namespace is provisioned and can be used.
+	SandboxReady SandboxPhase = "Ready"
+	// SandboxExpiring means the sandbox is ready, but will soon be reaped.
+	SandboxExpiring SandboxPhase = "Expiring"
+	// SandboxOverdue means the sandbox has manual expiry and has passed its expiration date.
+	SandboxOverdue SandboxPhase = "Overdue"
+	// SandboxHibernated means the workloads in the sandbox are scaled down.
+	SandboxHibernated SandboxPhase = "Hibernated"
+	// SandboxTerminating means the sandbox is being deleted.
+	SandboxTerminating SandboxPhase = "Terminating"
+)
+
+// PruneNamespacesAnnotation allows namespaces to be removed from a Sandbox, which deletes them with all their contents.
+const PruneNamespacesAnnotation = "sandboxer/prune-namespaces"
+
+// Condition types of a Sandbox
+const (
+	// ConditionNamespaceReady indicates whether the sandbox namespace exists and is active.
+	ConditionNamespaceReady = "NamespaceReady"
+	// ConditionRBACReady indicates whether the sandbox owner has been granted access to the namespace.
+	ConditionRBACReady = "RBACReady"
+	// ConditionExpiring indicates whether the sandbox will soon be reaped.
+	ConditionExpiring = "Expiring"
+	// ConditionOverdue indicates whether a sandbox with manual expiry has passed its expiration date.
+	ConditionOverdue = "Overdue"
+	// ConditionConflict indicates whether the sandbox namespace already exists and cannot be adopted.
+	ConditionConflict = "Conflict"
+	// ConditionIdle indicates whether the sandbox will soon be reaped because there is no activity in it.
+	ConditionIdle = "Idle"
+	// ConditionHibernated indicates whether the workloads in the sandbox are scaled down.
+	ConditionHibernated = "Hibernated"
+	// ConditionWaking indicates whether the workloads in the sandbox are being restored after hibernation.
+	ConditionWaking = "Waking"
+	// ConditionTemplateReady indicates whether the SandboxTemplate of the sandbox exists and its objects are applied.
+	ConditionTemplateReady = "TemplateReady"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:resource:scope=Cluster,shortName=sb
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.user`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.status.namespace`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.expiration_date`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:storageversion
 // +genclient
 // +genclient:nonNamespaced
 
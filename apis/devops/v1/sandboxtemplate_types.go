@@ -0,0 +1,82 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SandboxTemplateSpec defines the desired state of SandboxTemplate
+type SandboxTemplateSpec struct {
+	// Defaults are used for the fields that are left empty in the spec of a Sandbox using this template
+	// +optional
+	Defaults SandboxDefaults `json:"defaults,omitempty"`
+
+	// Objects are the manifests that are created in the namespace of every Sandbox using this template.
+	// String values in the manifests are rendered as Go templates, with the Sandbox and Namespace as context.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Objects []runtime.RawExtension `json:"objects,omitempty"`
+}
+
+// SandboxDefaults are the SandboxSpec fields that can be defaulted by a SandboxTemplate
+type SandboxDefaults struct {
+	// Groups are granted the same permissions in the sandbox as the User
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Isolation determines which network traffic is allowed to and from the sandbox
+	// +optional
+	Isolation IsolationMode `json:"isolation,omitempty"`
+
+	// Resources is the resource budget of the sandbox
+	// +optional
+	Resources *SandboxResources `json:"resources,omitempty"`
+}
+
+// SandboxTemplateReference refers to a SandboxTemplate by name
+type SandboxTemplateReference struct {
+	// Name of the SandboxTemplate
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +genclient
+// +genclient:nonNamespaced
+
+// SandboxTemplate is the Schema for the sandboxtemplates API
+type SandboxTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SandboxTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SandboxTemplateList contains a list of SandboxTemplate
+type SandboxTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SandboxTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SandboxTemplate{}, &SandboxTemplateList{})
+}
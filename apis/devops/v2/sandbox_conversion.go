@@ -0,0 +1,152 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts this Sandbox to the hub version (v1).
+func (src *Sandbox) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*devopsv1.Sandbox)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.User = src.Spec.User
+	dst.Spec.SlackId = src.Spec.Notification.SlackID
+	if src.Spec.Notification.Warnings != nil {
+		warnings := devopsv1.WarningPolicy(*src.Spec.Notification.Warnings)
+		dst.Spec.Warnings = &warnings
+	}
+	dst.Spec.ExpirationDate = src.Spec.TTL.ExpirationDate
+	dst.Spec.ManualExpiry = src.Spec.TTL.Policy == ExpirationPolicyNotify
+	dst.Spec.DisableIdleReaping = src.Spec.TTL.DisableIdleReaping
+	if src.Spec.TemplateRef != nil {
+		dst.Spec.TemplateRef = &devopsv1.SandboxTemplateReference{Name: src.Spec.TemplateRef.Name}
+	}
+	dst.Spec.Groups = src.Spec.Groups
+	dst.Spec.Isolation = devopsv1.IsolationMode(src.Spec.Isolation)
+	dst.Spec.Secrets = src.Spec.Secrets
+	dst.Spec.PreDeleteHooks = nil
+	for _, hook := range src.Spec.PreDeleteHooks {
+		dst.Spec.PreDeleteHooks = append(dst.Spec.PreDeleteHooks, devopsv1.SandboxHook(hook))
+	}
+	if src.Spec.Resources != nil {
+		resources := devopsv1.SandboxResources(*src.Spec.Resources)
+		dst.Spec.Resources = &resources
+	}
+	dst.Spec.Namespaces = src.Spec.Namespaces
+	dst.Spec.Hibernate = src.Spec.Hibernate
+	if src.Spec.Uptime != nil {
+		uptime := devopsv1.UptimeSchedule(*src.Spec.Uptime)
+		dst.Spec.Uptime = &uptime
+	}
+
+	dst.Status.NamespaceStatus = src.Status.NamespaceStatus
+	dst.Status.LastNotification = src.Status.LastNotification
+	dst.Status.Phase = devopsv1.SandboxPhase(src.Status.Phase)
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Namespace = src.Status.Namespace
+	dst.Status.Namespaces = nil
+	for _, ns := range src.Status.Namespaces {
+		dst.Status.Namespaces = append(dst.Status.Namespaces, devopsv1.SandboxNamespaceStatus(ns))
+	}
+	dst.Status.ExpirationDate = src.Status.ExpirationDate
+	dst.Status.LastActivity = src.Status.LastActivity
+	dst.Status.Conditions = src.Status.Conditions
+	if src.Status.Teardown != nil {
+		dst.Status.Teardown = &devopsv1.SandboxTeardownStatus{
+			Step:               devopsv1.TeardownStep(src.Status.Teardown.Step),
+			BlockingFinalizers: src.Status.Teardown.BlockingFinalizers,
+			Message:            src.Status.Teardown.Message,
+		}
+	}
+	if src.Status.Usage != nil {
+		usage := devopsv1.SandboxUsage(*src.Status.Usage)
+		dst.Status.Usage = &usage
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version.
+func (dst *Sandbox) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*devopsv1.Sandbox)
+
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.User = src.Spec.User
+	dst.Spec.Notification.SlackID = src.Spec.SlackId
+	if src.Spec.Warnings != nil {
+		warnings := WarningPolicy(*src.Spec.Warnings)
+		dst.Spec.Notification.Warnings = &warnings
+	}
+	dst.Spec.TTL.ExpirationDate = src.Spec.ExpirationDate
+	// The policy is always set, so that a v2 client sees the same policy whether or not it was given on creation
+	dst.Spec.TTL.Policy = ExpirationPolicyDelete
+	if src.Spec.ManualExpiry {
+		dst.Spec.TTL.Policy = ExpirationPolicyNotify
+	}
+	dst.Spec.TTL.DisableIdleReaping = src.Spec.DisableIdleReaping
+	if src.Spec.TemplateRef != nil {
+		dst.Spec.TemplateRef = &SandboxTemplateReference{Name: src.Spec.TemplateRef.Name}
+	}
+	dst.Spec.Groups = src.Spec.Groups
+	dst.Spec.Isolation = IsolationMode(src.Spec.Isolation)
+	dst.Spec.Secrets = src.Spec.Secrets
+	dst.Spec.PreDeleteHooks = nil
+	for _, hook := range src.Spec.PreDeleteHooks {
+		dst.Spec.PreDeleteHooks = append(dst.Spec.PreDeleteHooks, SandboxHook(hook))
+	}
+	if src.Spec.Resources != nil {
+		resources := SandboxResources(*src.Spec.Resources)
+		dst.Spec.Resources = &resources
+	}
+	dst.Spec.Namespaces = src.Spec.Namespaces
+	dst.Spec.Hibernate = src.Spec.Hibernate
+	if src.Spec.Uptime != nil {
+		uptime := UptimeSchedule(*src.Spec.Uptime)
+		dst.Spec.Uptime = &uptime
+	}
+
+	dst.Status.NamespaceStatus = src.Status.NamespaceStatus
+	dst.Status.LastNotification = src.Status.LastNotification
+	dst.Status.Phase = SandboxPhase(src.Status.Phase)
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Namespace = src.Status.Namespace
+	dst.Status.Namespaces = nil
+	for _, ns := range src.Status.Namespaces {
+		dst.Status.Namespaces = append(dst.Status.Namespaces, SandboxNamespaceStatus(ns))
+	}
+	dst.Status.ExpirationDate = src.Status.ExpirationDate
+	dst.Status.LastActivity = src.Status.LastActivity
+	dst.Status.Conditions = src.Status.Conditions
+	if src.Status.Teardown != nil {
+		dst.Status.Teardown = &SandboxTeardownStatus{
+			Step:               TeardownStep(src.Status.Teardown.Step),
+			BlockingFinalizers: src.Status.Teardown.BlockingFinalizers,
+			Message:            src.Status.Teardown.Message,
+		}
+	}
+	if src.Status.Usage != nil {
+		usage := SandboxUsage(*src.Status.Usage)
+		dst.Status.Usage = &usage
+	}
+
+	return nil
+}
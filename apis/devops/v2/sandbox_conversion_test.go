@@ -0,0 +1,141 @@
+package v2
+
+import (
+	"testing"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRoundTripFromHub(t *testing.T) {
+	expirationDate := metav1.NewTime(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	cpu := resource.MustParse("2")
+	pvcs := int64(5)
+
+	var tests = map[string]*devopsv1.Sandbox{
+		"Minimal sandbox": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec:       devopsv1.SandboxSpec{User: "jdoe", SlackId: "U0123ABCD"},
+		},
+		"Full sandbox": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1", Labels: map[string]string{"team": "devops"}},
+			Spec: devopsv1.SandboxSpec{
+				User:               "jdoe",
+				SlackId:            "U0123ABCD",
+				ExpirationDate:     &expirationDate,
+				ManualExpiry:       true,
+				DisableIdleReaping: true,
+				TemplateRef:        &devopsv1.SandboxTemplateReference{Name: "microservices"},
+				Groups:             []string{"devops"},
+				Isolation:          devopsv1.IsolationIsolated,
+				Secrets:            []corev1.SecretReference{{Namespace: "infra", Name: "registry"}},
+				PreDeleteHooks:     []devopsv1.SandboxHook{{Name: "backup", Image: "busybox", Args: []string{"-c", "true"}}},
+				Resources: &devopsv1.SandboxResources{
+					CPU:                    &cpu,
+					PersistentVolumeClaims: &pvcs,
+					DefaultLimit:           corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+				Namespaces: []string{"app", "data"},
+				Hibernate:  true,
+				Uptime:     &devopsv1.UptimeSchedule{Days: "Mon-Fri", Start: "07:00", End: "19:00", TimeZone: "Europe/Amsterdam"},
+			},
+			Status: devopsv1.SandboxStatus{
+				NamespaceStatus:    corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+				LastNotification:   &expirationDate,
+				Phase:              devopsv1.SandboxTerminating,
+				ObservedGeneration: 3,
+				Namespace:          "sandbox-jdoe-test-1-app",
+				Namespaces:         []devopsv1.SandboxNamespaceStatus{{Name: "sandbox-jdoe-test-1-app", Phase: corev1.NamespaceActive}},
+				ExpirationDate:     &expirationDate,
+				LastActivity:       &expirationDate,
+				Conditions:         []metav1.Condition{{Type: devopsv1.ConditionNamespaceReady, Status: metav1.ConditionTrue}},
+				Teardown: &devopsv1.SandboxTeardownStatus{
+					Step:               devopsv1.TeardownDeletingNamespace,
+					BlockingFinalizers: []string{"kubernetes"},
+					Message:            "Waiting for namespace sandbox-jdoe-test-1-app to be removed",
+				},
+				Usage: &devopsv1.SandboxUsage{
+					Requests:   corev1.ResourceList{corev1.ResourceCPU: cpu},
+					HourlyCost: "0.0600",
+					Currency:   "USD",
+					ObservedAt: &expirationDate,
+				},
+			},
+		},
+	}
+
+	for name, hub := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &Sandbox{}
+			assert.NilError(t, sandbox.ConvertFrom(hub))
+
+			converted := &devopsv1.Sandbox{}
+			assert.NilError(t, sandbox.ConvertTo(converted))
+			assert.DeepEqual(t, converted, hub)
+		})
+	}
+}
+
+func TestRoundTripToHub(t *testing.T) {
+	expirationDate := metav1.NewTime(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	var tests = map[string]*Sandbox{
+		"Expires by default": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec: SandboxSpec{
+				User:         "jdoe",
+				Notification: NotificationContact{SlackID: "U0123ABCD"},
+				TTL:          TTLPolicy{ExpirationDate: &expirationDate},
+			},
+		},
+		"Deletes once expired": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec: SandboxSpec{
+				User:         "jdoe",
+				Notification: NotificationContact{SlackID: "U0123ABCD"},
+				TTL:          TTLPolicy{ExpirationDate: &expirationDate, Policy: ExpirationPolicyDelete},
+			},
+		},
+		"Notifies once expired": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec: SandboxSpec{
+				User:         "jdoe",
+				Notification: NotificationContact{SlackID: "U0123ABCD"},
+				TTL:          TTLPolicy{ExpirationDate: &expirationDate, Policy: ExpirationPolicyNotify},
+				Isolation:    IsolationShared,
+			},
+		},
+		"Overrides warnings": {
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec: SandboxSpec{
+				User: "jdoe",
+				Notification: NotificationContact{SlackID: "U0123ABCD", Warnings: &WarningPolicy{
+					FirstWarning: &metav1.Duration{Duration: 72 * time.Hour},
+					Interval:     &metav1.Duration{Duration: 12 * time.Hour},
+				}},
+				TTL: TTLPolicy{ExpirationDate: &expirationDate},
+			},
+		},
+	}
+
+	for name, sandbox := range tests {
+		t.Run(name, func(t *testing.T) {
+			hub := &devopsv1.Sandbox{}
+			assert.NilError(t, sandbox.ConvertTo(hub))
+
+			// The policy defaults to Delete
+			expected := sandbox.DeepCopy()
+			if expected.Spec.TTL.Policy == "" {
+				expected.Spec.TTL.Policy = ExpirationPolicyDelete
+			}
+
+			converted := &Sandbox{}
+			assert.NilError(t, converted.ConvertFrom(hub))
+			assert.DeepEqual(t, converted, expected)
+		})
+	}
+}
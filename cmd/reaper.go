@@ -8,15 +8,35 @@ import (
 )
 
 func ReaperCommand() *cobra.Command {
+	var dryRun bool
+	var output string
+
 	cmd := &cobra.Command{
 		Use:   "reaper",
 		Short: "Reaper reaps namespaces that have exceeded their expiry date",
+		// Errors while reaping are not caused by the usage of the command
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			config := &reaper.Config{}
 			if err := envconfig.Process("", config); err != nil {
 				return err
 			}
 
+			if dryRun {
+				// A dry run does not notify, so it works without Slack
+				r, err := reaper.NewReaper(cmd.Context(), config, nil)
+				if err != nil {
+					return err
+				}
+
+				actions, err := r.Plan(cmd.Context())
+				if err != nil {
+					return err
+				}
+
+				return reaper.WritePlan(cmd.OutOrStdout(), actions, output)
+			}
+
 			slack, err := slack.NewSlacker()
 			if err != nil {
 				return err
@@ -27,10 +47,13 @@ func ReaperCommand() *cobra.Command {
 				return err
 			}
 
-			reaper.Run(cmd.Context())
-			return nil
+			return reaper.Run(cmd.Context())
 		},
 	}
 
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Print what would be done with every sandbox and the checks it is based on, without changing anything")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format of the dry run, one of table, json or yaml")
+
 	return cmd
 }
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stackvista/sandbox-operator/internal/report"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func ReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report on the sandboxes in the cluster",
+	}
+
+	cmd.AddCommand(costCommand())
+	return cmd
+}
+
+func costCommand() *cobra.Command {
+	var output, teamLabel string
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Report the resources and cost of the sandboxes per user and per team",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+			if err != nil {
+				return err
+			}
+
+			client, err := versioned.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			sandboxes, err := client.DevopsV1().Sandboxes().List(cmd.Context(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+
+			costs, err := report.Cost(sandboxes.Items, teamLabel)
+			if err != nil {
+				return err
+			}
+
+			return costs.Write(cmd.OutOrStdout(), output)
+		},
+	}
+
+	cmd.Flags().StringVar(&loadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file to use")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format, one of table, csv or json")
+	cmd.Flags().StringVar(&teamLabel, "team-label", "team", "The label on sandboxes that holds the team of their owner")
+
+	return cmd
+}
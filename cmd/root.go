@@ -19,6 +19,7 @@ func Execute(ctx context.Context) {
 	cmd := RootCommand()
 	cmd.AddCommand(SandboxCommand())
 	cmd.AddCommand(ReaperCommand())
+	cmd.AddCommand(ReportCommand())
 
 	if err := cmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
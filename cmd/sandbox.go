@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/stackvista/sandbox-operator/internal/notification/slack"
 	"github.com/stackvista/sandbox-operator/internal/sandbox"
 
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +18,34 @@ func SandboxCommand() *cobra.Command {
 		Use:   "sandbox",
 		Short: "Start the Sandbox controller",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := envconfig.Process("sandbox", &config.Controller); err != nil {
+				return err
+			}
+
+			if err := envconfig.Process("sandbox", &config.Webhook); err != nil {
+				return err
+			}
+
+			slacker, err := slack.NewSlacker()
+			if err != nil {
+				log.Ctx(cmd.Context()).Info().Err(err).Msg("Slack is not configured, Slack IDs will not be looked up")
+			} else {
+				config.SlackIdLookup = slacker
+			}
+
+			if config.EnableReaper || config.SlackInteractionsAddr != "" {
+				// The reaper shares its settings with the one-shot reaper command
+				if err := envconfig.Process("", &config.Reaper); err != nil {
+					return err
+				}
+
+				if slacker == nil {
+					return fmt.Errorf("the reaper needs Slack to notify the owners of sandboxes: %w", err)
+				}
+				config.Notifier = slacker
+				config.Slacker = slacker
+			}
+
 			return sandbox.StartOperator(cmd.Context(), config)
 		},
 	}
@@ -21,5 +54,11 @@ func SandboxCommand() *cobra.Command {
 	cmd.Flags().BoolVarP(&config.EnableLeaderElection, "enable-leader-election", "e", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	cmd.Flags().BoolVar(&config.EnableWebhooks, "enable-webhooks", true,
+		"Serve the admission webhooks for Sandboxes on port 9443.")
+	cmd.Flags().BoolVar(&config.EnableReaper, "enable-reaper", false,
+		"Reap sandboxes continuously in the controller manager, instead of with the reaper command.")
+	cmd.Flags().StringVar(&config.SlackInteractionsAddr, "slack-interactions-addr", "",
+		"The address on which the buttons in Slack notifications are handled, disabled if empty.")
 	return cmd
 }
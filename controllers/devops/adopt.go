@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// adoptAnnotation marks an existing namespace to be taken over by the Sandbox it is named after.
+const adoptAnnotation = "sandboxer/adopt"
+
+// canAdopt checks whether the Sandbox may take over an existing namespace that it does not control. This is the case
+// when the namespace was left behind by the Sandbox, i.e. it carries its labels and an owner reference to it, or when
+// it is explicitly annotated to be adopted. If not, the reason is returned.
+func canAdopt(sandbox *devopsv1.Sandbox, ns *corev1.Namespace) (bool, string) {
+	if controller := metav1.GetControllerOf(ns); controller != nil && !isSandboxReference(*controller, sandbox) {
+		return false, fmt.Sprintf("Namespace %s is controlled by %s %s", ns.Name, controller.Kind, controller.Name)
+	}
+
+	if ns.Annotations[adoptAnnotation] == "true" {
+		return true, ""
+	}
+
+	for _, ref := range ns.OwnerReferences {
+		if isSandboxReference(ref, sandbox) && containsAll(ns.Labels, sandboxLabels(sandbox)) {
+			return true, ""
+		}
+	}
+
+	return false, fmt.Sprintf("Namespace %s already exists and is not managed by the sandbox, annotate it with %s=true to adopt it",
+		ns.Name, adoptAnnotation)
+}
+
+// adoptNamespace replaces the stale owner references of the namespace by a controller reference to the Sandbox and
+// restores the managed labels and annotations.
+func (r *SandboxReconciler) adoptNamespace(ctx context.Context, sandbox *devopsv1.Sandbox, ns *corev1.Namespace) error {
+	refs := []metav1.OwnerReference{}
+	for _, ref := range ns.OwnerReferences {
+		if !isSandboxReference(ref, sandbox) {
+			refs = append(refs, ref)
+		}
+	}
+	ns.OwnerReferences = refs
+
+	if err := ctrl.SetControllerReference(sandbox, ns, r.Scheme); err != nil {
+		return err
+	}
+
+	ns.Labels = mergeMaps(ns.Labels, sandboxLabels(sandbox))
+	ns.Annotations = mergeMaps(ns.Annotations, namespaceAnnotations(sandbox))
+
+	return r.Update(ctx, ns)
+}
+
+// isSandboxReference checks whether the owner reference points to the given Sandbox. The UID is compared too, so
+// that a namespace of an earlier Sandbox with the same name is not mistaken for one of this Sandbox.
+func isSandboxReference(ref metav1.OwnerReference, sandbox *devopsv1.Sandbox) bool {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false
+	}
+
+	return gv.Group == devopsv1.GroupVersion.Group && ref.Kind == "Sandbox" && ref.Name == sandbox.Name &&
+		ref.UID == sandbox.UID
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestCanAdopt(t *testing.T) {
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	ownRef := metav1.OwnerReference{APIVersion: "devops.stackstate.com/v1", Kind: "Sandbox", Name: "test-1", UID: "1234"}
+	staleRef := metav1.OwnerReference{APIVersion: "devops.stackstate.com/v1", Kind: "Sandbox", Name: "test-1", UID: "5678", Controller: pBool(true)}
+	otherRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test-1", UID: "9012", Controller: pBool(true)}
+
+	var tests = map[string]struct {
+		labels      map[string]string
+		annotations map[string]string
+		refs        []metav1.OwnerReference
+		canAdopt    bool
+	}{
+		"Left behind by sandbox":             {sandboxLabels(sandbox), nil, []metav1.OwnerReference{ownRef}, true},
+		"Owner reference without labels":     {nil, nil, []metav1.OwnerReference{ownRef}, false},
+		"Labels without owner reference":     {sandboxLabels(sandbox), nil, nil, false},
+		"Annotated for adoption":             {nil, map[string]string{adoptAnnotation: "true"}, nil, true},
+		"Controlled by another object":       {nil, map[string]string{adoptAnnotation: "true"}, []metav1.OwnerReference{otherRef}, false},
+		"Left behind by earlier sandbox":     {sandboxLabels(sandbox), nil, []metav1.OwnerReference{staleRef}, false},
+		"Controlled by earlier sandbox":      {nil, map[string]string{adoptAnnotation: "true"}, []metav1.OwnerReference{staleRef}, false},
+		"Unrelated namespace with same name": {nil, nil, nil, false},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "sandbox-jdoe-test-1",
+					Labels:          data.labels,
+					Annotations:     data.annotations,
+					OwnerReferences: data.refs,
+				},
+			}
+
+			ok, reason := canAdopt(sandbox, ns)
+			assert.Equal(t, ok, data.canAdopt, reason)
+		})
+	}
+}
+
+func TestReconcileNamespaceConflict(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sandbox-jdoe-test-1"}}
+
+	r := newTestReconciler(t, sandbox, ns)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// The conflict is reported without an error, so that the Sandbox is not requeued
+	result, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.Assert(t, meta.IsStatusConditionTrue(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionConflict))
+
+	// Once annotated, the namespace is adopted
+	ns.Annotations = map[string]string{adoptAnnotation: "true"}
+	assert.NilError(t, r.Update(ctx, ns))
+
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	adopted := &corev1.Namespace{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: ns.Name}, adopted))
+	assert.Assert(t, metav1.IsControlledBy(adopted, getSandbox(t, r, sandbox.Name)))
+	assert.Assert(t, meta.FindStatusCondition(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionConflict) == nil)
+}
+
+func pBool(b bool) *bool {
+	return &b
+}
+
+func TestReconcileNewSandbox(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// A new Sandbox has no conditions yet, clearing the Conflict condition must not fail on it
+	assert.Equal(t, len(sandbox.Status.Conditions), 0)
+	_, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	ns := &corev1.Namespace{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: "sandbox-jdoe-test-1"}, ns))
+	assert.Assert(t, metav1.IsControlledBy(ns, getSandbox(t, r, sandbox.Name)))
+	assert.Assert(t, meta.FindStatusCondition(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionConflict) == nil)
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Config holds the operator-wide settings of the SandboxReconciler.
+type Config struct {
+	DefaultCPU                    Quantity `split_words:"true" default:"4"`
+	DefaultMemory                 Quantity `split_words:"true" default:"8Gi"`
+	DefaultStorage                Quantity `split_words:"true" default:"50Gi"`
+	DefaultPods                   int64    `split_words:"true" default:"50"`
+	DefaultServices               int64    `split_words:"true" default:"20"`
+	DefaultPersistentVolumeClaims int64    `split_words:"true" default:"10"`
+	DefaultContainerCPURequest    Quantity `split_words:"true" default:"100m"`
+	DefaultContainerMemoryRequest Quantity `split_words:"true" default:"128Mi"`
+	DefaultContainerCPULimit      Quantity `split_words:"true" default:"500m"`
+	DefaultContainerMemoryLimit   Quantity `split_words:"true" default:"512Mi"`
+	OwnerClusterRole              string   `split_words:"true" default:"admin"`
+
+	DefaultIsolation devopsv1.IsolationMode `split_words:"true" default:"shared"`
+	SharedNamespaces []string               `split_words:"true"` // Namespaces reachable from sandboxes in shared isolation mode
+
+	DNSNamespace string            `split_words:"true" default:"kube-system"`      // Namespace of the cluster DNS
+	DNSPodLabels map[string]string `split_words:"true" default:"k8s-app:kube-dns"` // Labels of the cluster DNS pods, as key:value pairs
+
+	SharedSecrets  pkgsandbox.SecretReferences `split_words:"true"` // Secrets copied into every sandbox, as namespace/name
+	AllowedSecrets pkgsandbox.SecretReferences `split_words:"true"` // Secrets that Sandboxes may request to be copied, as namespace/name
+
+	UsageInterval time.Duration `split_words:"true" default:"15m"`                                  // How often the resource usage of sandboxes is aggregated
+	Prices        PriceTable    `split_words:"true" default:"cpu:0.03,memory:0.004,storage:0.0001"` // Price per core, or GiB, per hour
+	Currency      string        `split_words:"true" default:"USD"`
+}
+
+// Quantity wraps a resource.Quantity so that it can be read from the environment.
+type Quantity struct {
+	resource.Quantity
+}
+
+// Decode implements envconfig.Decoder
+func (q *Quantity) Decode(value string) error {
+	parsed, err := resource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+
+	q.Quantity = parsed
+	return nil
+}
+
+// Copy returns a pointer to a deep copy of the wrapped resource.Quantity.
+func (q *Quantity) Copy() *resource.Quantity {
+	c := q.Quantity.DeepCopy()
+	return &c
+}
+
+// PriceTable is the price per hour of a core of CPU, or a GiB of memory or storage. It can be read from the environment
+// as comma separated resource:price pairs.
+type PriceTable map[corev1.ResourceName]float64
+
+// Decode implements envconfig.Decoder
+func (p *PriceTable) Decode(value string) error {
+	prices := PriceTable{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid price %q, expected resource:price", pair)
+		}
+
+		resourceName := corev1.ResourceName(strings.TrimSpace(parts[0]))
+		if resourceName != corev1.ResourceCPU && resourceName != corev1.ResourceMemory && resourceName != corev1.ResourceStorage {
+			return fmt.Errorf("invalid price %q, only cpu, memory and storage can be priced", pair)
+		}
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("invalid price %q, expected resource:price", pair)
+		}
+
+		prices[resourceName] = price
+	}
+
+	*p = prices
+	return nil
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestDecodePriceTable(t *testing.T) {
+	var tests = map[string]struct {
+		value    string
+		expected PriceTable
+		err      string
+	}{
+		"Empty":           {"", PriceTable{}, ""},
+		"All resources":   {"cpu:0.03, memory:0.004,storage:0.0001", PriceTable{"cpu": 0.03, "memory": 0.004, "storage": 0.0001}, ""},
+		"Unknown":         {"gpu:1.5", nil, "only cpu, memory and storage can be priced"},
+		"Invalid price":   {"cpu:cheap", nil, "expected resource:price"},
+		"Negative price":  {"cpu:-1", nil, "expected resource:price"},
+		"Missing a price": {"cpu", nil, "expected resource:price"},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			prices := PriceTable{}
+			err := prices.Decode(data.value)
+			if data.err != "" {
+				assert.ErrorContains(t, err, data.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, prices, data.expected)
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// replicasAnnotation records the replica count of a Deployment or StatefulSet before it was hibernated.
+	replicasAnnotation = "sandboxer/hibernated-replicas"
+	// suspendAnnotation records whether a CronJob was suspended before it was hibernated.
+	suspendAnnotation = "sandboxer/hibernated-suspend"
+
+	wakingRequeueInterval = 15 * time.Second
+)
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;update;patch
+
+// reconcileHibernation hibernates the workloads in the namespaces of the Sandbox while it is marked for hibernation or
+// outside of its uptime schedule, and restores them once it is not. The Sandbox is requeued at the next transition of
+// its schedule, and while the restored workloads are not yet ready to report their progress in the Waking condition.
+func (r *SandboxReconciler) reconcileHibernation(ctx context.Context, sandbox *devopsv1.Sandbox, namespaces []*corev1.Namespace) (ctrl.Result, error) {
+	now := clock.Ctx(ctx).Now()
+	result := ctrl.Result{}
+
+	hibernate := sandbox.Spec.Hibernate
+	reason, message := "ScaledDown", "Workloads are scaled down and CronJobs are suspended"
+
+	if sandbox.Spec.Uptime != nil && !sandbox.Spec.Hibernate {
+		uptime, err := pkgsandbox.ParseUptime(sandbox.Spec.Uptime)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("invalid uptime schedule: %w", err)
+		}
+
+		next := uptime.NextTransition(now)
+		if !next.IsZero() {
+			result.RequeueAfter = next.Sub(now)
+		}
+
+		if !uptime.IsUp(now) {
+			hibernate = true
+			reason, message = "OutsideUptime", "Workloads are scaled down outside of the uptime schedule"
+			if !next.IsZero() {
+				message = fmt.Sprintf("%s until %s", message, next.Format(time.RFC3339))
+			}
+		}
+	}
+
+	if hibernate {
+		for _, ns := range namespaces {
+			if err := r.hibernate(ctx, ns.Name); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionHibernated, metav1.ConditionTrue, reason, message, now)
+		pkgsandbox.RemoveCondition(sandbox, devopsv1.ConditionWaking)
+		return result, nil
+	}
+
+	// Only a Sandbox that is hibernated or still waking up needs its workloads restored
+	conditions := sandbox.Status.Conditions
+	if !meta.IsStatusConditionTrue(conditions, devopsv1.ConditionHibernated) &&
+		!meta.IsStatusConditionTrue(conditions, devopsv1.ConditionWaking) {
+		return result, nil
+	}
+
+	notReady := []string{}
+	for _, ns := range namespaces {
+		pending, err := r.wake(ctx, ns.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		notReady = append(notReady, pending...)
+	}
+
+	pkgsandbox.SetCondition(sandbox, devopsv1.ConditionHibernated, metav1.ConditionFalse, "Restored",
+		"Workloads are restored to their original scale", now)
+
+	if len(notReady) > 0 {
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionWaking, metav1.ConditionTrue, "WorkloadsStarting",
+			fmt.Sprintf("Waiting for %s to become ready", strings.Join(notReady, ", ")), now)
+		if result.RequeueAfter == 0 || result.RequeueAfter > wakingRequeueInterval {
+			result.RequeueAfter = wakingRequeueInterval
+		}
+		return result, nil
+	}
+
+	pkgsandbox.SetCondition(sandbox, devopsv1.ConditionWaking, metav1.ConditionFalse, "WorkloadsReady", "", now)
+	return result, nil
+}
+
+// hibernate scales the Deployments and StatefulSets in the namespace to zero and suspends its CronJobs. Their original
+// state is recorded in annotations, so that wake can restore it.
+func (r *SandboxReconciler) hibernate(ctx context.Context, namespace string) error {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Reader.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if scaleDown(d, &d.Spec.Replicas) {
+			if err := r.Update(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.Reader.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if scaleDown(s, &s.Spec.Replicas) {
+			if err := r.Update(ctx, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	cronJobs := &batchv1beta1.CronJobList{}
+	if err := r.Reader.List(ctx, cronJobs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		if suspend(c, &c.Spec.Suspend) {
+			if err := r.Update(ctx, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// wake restores the Deployments, StatefulSets and CronJobs in the namespace to the state they had before hibernation,
+// and returns the Deployments and StatefulSets that are not yet ready.
+func (r *SandboxReconciler) wake(ctx context.Context, namespace string) ([]string, error) {
+	notReady := []string{}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Reader.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		changed, err := scaleUp(d, &d.Spec.Replicas)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := r.Update(ctx, d); err != nil {
+				return nil, err
+			}
+		}
+
+		if d.Status.ObservedGeneration < d.Generation || d.Status.ReadyReplicas < replicaCount(d.Spec.Replicas) {
+			notReady = append(notReady, fmt.Sprintf("Deployment %s/%s", namespace, d.Name))
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.Reader.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		changed, err := scaleUp(s, &s.Spec.Replicas)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := r.Update(ctx, s); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.Status.ObservedGeneration < s.Generation || s.Status.ReadyReplicas < replicaCount(s.Spec.Replicas) {
+			notReady = append(notReady, fmt.Sprintf("StatefulSet %s/%s", namespace, s.Name))
+		}
+	}
+
+	cronJobs := &batchv1beta1.CronJobList{}
+	if err := r.Reader.List(ctx, cronJobs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		changed, err := resume(c, &c.Spec.Suspend)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := r.Update(ctx, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return notReady, nil
+}
+
+// scaleDown records the replica count in the annotations of the object, unless it is already hibernated, and scales
+// it to zero. It returns whether the object was changed.
+func scaleDown(obj metav1.Object, replicas **int32) bool {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[replicasAnnotation]; ok && replicaCount(*replicas) == 0 {
+		return false
+	}
+
+	if _, ok := annotations[replicasAnnotation]; !ok {
+		obj.SetAnnotations(mergeMaps(annotations, map[string]string{
+			replicasAnnotation: strconv.Itoa(int(replicaCount(*replicas))),
+		}))
+	}
+
+	zero := int32(0)
+	*replicas = &zero
+	return true
+}
+
+// scaleUp restores the replica count that was recorded by scaleDown. It returns whether the object was changed.
+func scaleUp(obj metav1.Object, replicas **int32) (bool, error) {
+	value, ok := obj.GetAnnotations()[replicasAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	count, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation on %s: %w", replicasAnnotation, obj.GetName(), err)
+	}
+
+	original := int32(count)
+	*replicas = &original
+	removeAnnotation(obj, replicasAnnotation)
+	return true, nil
+}
+
+// suspend records whether the CronJob was suspended in its annotations, unless it is already hibernated, and
+// suspends it. It returns whether the object was changed.
+func suspend(obj metav1.Object, suspended **bool) bool {
+	annotations := obj.GetAnnotations()
+	isSuspended := *suspended != nil && **suspended
+	if _, ok := annotations[suspendAnnotation]; ok && isSuspended {
+		return false
+	}
+
+	if _, ok := annotations[suspendAnnotation]; !ok {
+		obj.SetAnnotations(mergeMaps(annotations, map[string]string{
+			suspendAnnotation: strconv.FormatBool(isSuspended),
+		}))
+	}
+
+	t := true
+	*suspended = &t
+	return true
+}
+
+// resume restores the suspend flag that was recorded by suspend. It returns whether the object was changed.
+func resume(obj metav1.Object, suspended **bool) (bool, error) {
+	value, ok := obj.GetAnnotations()[suspendAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	original, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation on %s: %w", suspendAnnotation, obj.GetName(), err)
+	}
+
+	*suspended = &original
+	removeAnnotation(obj, suspendAnnotation)
+	return true, nil
+}
+
+// keepHibernated keeps a template object that is hibernated scaled down or suspended, so that applying the template
+// does not wake it up.
+func keepHibernated(obj *unstructured.Unstructured) error {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[replicasAnnotation]; ok {
+		if err := unstructured.SetNestedField(obj.Object, int64(0), "spec", "replicas"); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := annotations[suspendAnnotation]; ok {
+		if err := unstructured.SetNestedField(obj.Object, true, "spec", "suspend"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replicaCount returns the replica count, which defaults to 1 if it is not set.
+func replicaCount(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+
+	return *replicas
+}
+
+func removeAnnotation(obj metav1.Object, key string) {
+	annotations := obj.GetAnnotations()
+	delete(annotations, key)
+	obj.SetAnnotations(annotations)
+}
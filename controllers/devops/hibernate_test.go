@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestReconcileHibernation(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe", Hibernate: true},
+	}
+
+	namespace := "sandbox-jdoe-test-1"
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pInt32(3)},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "db"},
+	}
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "backup"},
+		Spec:       batchv1beta1.CronJobSpec{Schedule: "@daily", Suspend: pBool(false)},
+	}
+
+	r := newTestReconciler(t, sandbox, deployment, statefulSet, cronJob)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// Hibernating is idempotent, the original state is only recorded once
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(ctx, req)
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "web"}, deployment))
+	assert.Equal(t, *deployment.Spec.Replicas, int32(0))
+	assert.Equal(t, deployment.Annotations[replicasAnnotation], "3")
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "db"}, statefulSet))
+	assert.Equal(t, *statefulSet.Spec.Replicas, int32(0))
+	assert.Equal(t, statefulSet.Annotations[replicasAnnotation], "1")
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "backup"}, cronJob))
+	assert.Equal(t, *cronJob.Spec.Suspend, true)
+	assert.Equal(t, cronJob.Annotations[suspendAnnotation], "false")
+
+	updated := getSandbox(t, r, sandbox.Name)
+	assert.Assert(t, meta.IsStatusConditionTrue(updated.Status.Conditions, devopsv1.ConditionHibernated))
+
+	// Waking up restores the original state, and waits for the workloads to become ready
+	updated.Spec.Hibernate = false
+	assert.NilError(t, r.Update(ctx, updated))
+
+	result, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, wakingRequeueInterval)
+
+	deployment = &appsv1.Deployment{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "web"}, deployment))
+	assert.Equal(t, *deployment.Spec.Replicas, int32(3))
+	assert.Assert(t, deployment.Annotations[replicasAnnotation] == "")
+	statefulSet = &appsv1.StatefulSet{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "db"}, statefulSet))
+	assert.Equal(t, *statefulSet.Spec.Replicas, int32(1))
+	cronJob = &batchv1beta1.CronJob{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "backup"}, cronJob))
+	assert.Equal(t, *cronJob.Spec.Suspend, false)
+	assert.Assert(t, cronJob.Annotations[suspendAnnotation] == "")
+
+	updated = getSandbox(t, r, sandbox.Name)
+	assert.Assert(t, meta.IsStatusConditionFalse(updated.Status.Conditions, devopsv1.ConditionHibernated))
+	assert.Assert(t, meta.IsStatusConditionTrue(updated.Status.Conditions, devopsv1.ConditionWaking))
+
+	deployment.Status.ReadyReplicas = 3
+	assert.NilError(t, r.Status().Update(ctx, deployment))
+	statefulSet.Status.ReadyReplicas = 1
+	assert.NilError(t, r.Status().Update(ctx, statefulSet))
+
+	result, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.Assert(t, meta.IsStatusConditionFalse(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionWaking))
+}
+
+func TestReconcileUptime(t *testing.T) {
+	c := clk.NewMock()
+	// 2021-03-01 is a Monday
+	c.Set(time.Date(2021, 3, 1, 20, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec: devopsv1.SandboxSpec{
+			User:   "jdoe",
+			Uptime: &devopsv1.UptimeSchedule{Days: "Mon-Fri", Start: "07:00", End: "19:00"},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "sandbox-jdoe-test-1", Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pInt32(2)},
+	}
+	key := types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}
+
+	r := newTestReconciler(t, sandbox, deployment)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// Outside the window the sandbox is scaled down until the window starts
+	result, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, 11*time.Hour)
+
+	deployment = &appsv1.Deployment{}
+	assert.NilError(t, r.Get(ctx, key, deployment))
+	assert.Equal(t, *deployment.Spec.Replicas, int32(0))
+
+	condition := meta.FindStatusCondition(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionHibernated)
+	assert.Equal(t, condition.Reason, "OutsideUptime")
+
+	// Once the window starts the sandbox is restored
+	c.Add(result.RequeueAfter)
+	result, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, wakingRequeueInterval)
+
+	deployment = &appsv1.Deployment{}
+	assert.NilError(t, r.Get(ctx, key, deployment))
+	assert.Equal(t, *deployment.Spec.Replicas, int32(2))
+
+	// Once the workloads are ready, the sandbox is requeued when the window ends
+	deployment.Status.ReadyReplicas = 2
+	assert.NilError(t, r.Status().Update(ctx, deployment))
+
+	result, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, 12*time.Hour)
+}
+
+func pInt32(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	networkPolicyName = "sandbox-isolation"
+
+	// namespaceNameLabel is set on every namespace by Kubernetes (1.21+) and contains the namespace name.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileNetworkPolicy creates, updates or removes the NetworkPolicy that isolates a sandbox namespace
+// according to the IsolationMode of the Sandbox.
+func (r *SandboxReconciler) reconcileNetworkPolicy(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) error {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName,
+			Namespace: namespace,
+		},
+	}
+
+	mode := r.isolationMode(sandbox)
+	if mode == devopsv1.IsolationOpen {
+		if err := r.Delete(ctx, policy); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		policy.Labels = sandboxLabels(sandbox)
+		policy.Spec = r.networkPolicySpec(mode, pkgsandbox.NamespaceNames(sandbox))
+
+		return ctrl.SetControllerReference(sandbox, policy, r.Scheme)
+	})
+
+	return err
+}
+
+// isolationMode returns the IsolationMode of the Sandbox, falling back to the operator-wide default.
+func (r *SandboxReconciler) isolationMode(sandbox *devopsv1.Sandbox) devopsv1.IsolationMode {
+	if sandbox.Spec.Isolation != "" {
+		return sandbox.Spec.Isolation
+	}
+
+	return r.Config.DefaultIsolation
+}
+
+// networkPolicySpec constructs a policy that selects all pods in the namespace and denies all traffic, except
+// traffic within the namespaces of the sandbox, DNS lookups to the cluster DNS and, in shared mode, traffic to and
+// from the shared-service namespaces.
+func (r *SandboxReconciler) networkPolicySpec(mode devopsv1.IsolationMode, namespaces []string) networkingv1.NetworkPolicySpec {
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dns := intstr.FromInt(53)
+
+	sameNamespace := []networkingv1.NetworkPolicyPeer{
+		{PodSelector: &metav1.LabelSelector{}},
+	}
+
+	if len(namespaces) > 1 {
+		sameNamespace = append(sameNamespace, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      namespaceNameLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   namespaces,
+					},
+				},
+			},
+		})
+	}
+
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{
+			networkingv1.PolicyTypeIngress,
+			networkingv1.PolicyTypeEgress,
+		},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{From: sameNamespace},
+		},
+		Egress: []networkingv1.NetworkPolicyEgressRule{
+			{To: sameNamespace},
+			{
+				To: []networkingv1.NetworkPolicyPeer{
+					{
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{namespaceNameLabel: r.Config.DNSNamespace},
+						},
+						PodSelector: &metav1.LabelSelector{MatchLabels: r.Config.DNSPodLabels},
+					},
+				},
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &udp, Port: &dns},
+					{Protocol: &tcp, Port: &dns},
+				},
+			},
+		},
+	}
+
+	if mode == devopsv1.IsolationShared && len(r.Config.SharedNamespaces) > 0 {
+		shared := []networkingv1.NetworkPolicyPeer{
+			{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      namespaceNameLabel,
+							Operator: metav1.LabelSelectorOpIn,
+							Values:   r.Config.SharedNamespaces,
+						},
+					},
+				},
+			},
+		}
+
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: shared})
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{To: shared})
+	}
+
+	return spec
+}
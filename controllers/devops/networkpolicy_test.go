@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestNetworkPolicySpec(t *testing.T) {
+	reconciler := &SandboxReconciler{
+		Config: &Config{
+			DefaultIsolation: devopsv1.IsolationShared,
+			SharedNamespaces: []string{"monitoring", "ingress"},
+			DNSNamespace:     "kube-system",
+			DNSPodLabels:     map[string]string{"k8s-app": "kube-dns"},
+		},
+	}
+
+	var tests = map[string]struct {
+		isolation  devopsv1.IsolationMode
+		namespaces []string
+		ingress    [][]string
+		egress     [][]string
+	}{
+		"Isolated allows namespace and DNS only": {devopsv1.IsolationIsolated, []string{"sandbox-test"},
+			[][]string{{"pods in namespace"}},
+			[][]string{{"pods in namespace"}, {"DNS"}}},
+		"Shared allows shared namespaces": {devopsv1.IsolationShared, []string{"sandbox-test"},
+			[][]string{{"pods in namespace"}, {"namespaces monitoring,ingress"}},
+			[][]string{{"pods in namespace"}, {"DNS"}, {"namespaces monitoring,ingress"}}},
+		"Default isolation falls back to operator": {"", []string{"sandbox-test"},
+			[][]string{{"pods in namespace"}, {"namespaces monitoring,ingress"}},
+			[][]string{{"pods in namespace"}, {"DNS"}, {"namespaces monitoring,ingress"}}},
+		"Multiple namespaces reach each other": {devopsv1.IsolationIsolated, []string{"sandbox-test-app", "sandbox-test-data"},
+			[][]string{{"pods in namespace", "namespaces sandbox-test-app,sandbox-test-data"}},
+			[][]string{{"pods in namespace", "namespaces sandbox-test-app,sandbox-test-data"}, {"DNS"}}},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{Spec: devopsv1.SandboxSpec{Isolation: data.isolation}}
+			spec := reconciler.networkPolicySpec(reconciler.isolationMode(sandbox), data.namespaces)
+
+			ingress := [][]string{}
+			for _, rule := range spec.Ingress {
+				assert.Equal(t, len(rule.Ports), 0)
+				ingress = append(ingress, describePeers(rule.From))
+			}
+
+			egress := [][]string{}
+			for _, rule := range spec.Egress {
+				if len(rule.Ports) > 0 {
+					assert.Equal(t, describePorts(rule.Ports), "UDP/53,TCP/53")
+					assert.DeepEqual(t, describePeers(rule.To), []string{"pods k8s-app=kube-dns in namespace kube-system"})
+					egress = append(egress, []string{"DNS"})
+					continue
+				}
+				egress = append(egress, describePeers(rule.To))
+			}
+
+			assert.DeepEqual(t, ingress, data.ingress)
+			assert.DeepEqual(t, egress, data.egress)
+			assert.Equal(t, len(spec.PodSelector.MatchLabels)+len(spec.PodSelector.MatchExpressions), 0)
+			assert.DeepEqual(t, spec.PolicyTypes, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress})
+		})
+	}
+}
+
+// describePeers describes which pods the peers select, either all pods in the own namespace, all pods in a list of
+// namespaces selected by name, or the labeled pods in a namespace selected by name.
+func describePeers(peers []networkingv1.NetworkPolicyPeer) []string {
+	described := []string{}
+	for _, peer := range peers {
+		switch {
+		case peer.PodSelector != nil && peer.NamespaceSelector == nil && peer.IPBlock == nil &&
+			len(peer.PodSelector.MatchLabels)+len(peer.PodSelector.MatchExpressions) == 0:
+			described = append(described, "pods in namespace")
+		case peer.NamespaceSelector != nil && peer.PodSelector == nil && len(peer.NamespaceSelector.MatchLabels) == 0 &&
+			len(peer.NamespaceSelector.MatchExpressions) == 1 &&
+			peer.NamespaceSelector.MatchExpressions[0].Key == namespaceNameLabel &&
+			peer.NamespaceSelector.MatchExpressions[0].Operator == "In":
+			described = append(described, "namespaces "+strings.Join(peer.NamespaceSelector.MatchExpressions[0].Values, ","))
+		case peer.NamespaceSelector != nil && peer.PodSelector != nil && peer.IPBlock == nil &&
+			len(peer.NamespaceSelector.MatchLabels) == 1 && len(peer.NamespaceSelector.MatchExpressions) == 0 &&
+			len(peer.PodSelector.MatchExpressions) == 0:
+			labels := []string{}
+			for key, value := range peer.PodSelector.MatchLabels {
+				labels = append(labels, key+"="+value)
+			}
+			sort.Strings(labels)
+			described = append(described, fmt.Sprintf("pods %s in namespace %s",
+				strings.Join(labels, ","), peer.NamespaceSelector.MatchLabels[namespaceNameLabel]))
+		default:
+			described = append(described, fmt.Sprintf("unexpected peer %v", peer))
+		}
+	}
+	return described
+}
+
+func describePorts(ports []networkingv1.NetworkPolicyPort) string {
+	described := []string{}
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		if port.Protocol != nil {
+			protocol = *port.Protocol
+		}
+		described = append(described, fmt.Sprintf("%s/%s", protocol, port.Port.String()))
+	}
+	return strings.Join(described, ",")
+}
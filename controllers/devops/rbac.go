@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const roleBindingName = "sandbox-owner"
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// The operator may only bind the default OwnerClusterRole, the rule in config/rbac/role.yaml has to be patched when
+// another ClusterRole is configured.
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=admin
+
+// reconcileRoleBinding creates or updates the RoleBinding that grants the Sandbox owner (and its groups)
+// the configured ClusterRole in the sandbox namespace.
+func (r *SandboxReconciler) reconcileRoleBinding(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) error {
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     r.Config.OwnerClusterRole,
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleBindingName,
+			Namespace: namespace,
+		},
+	}
+
+	// The RoleRef of a RoleBinding is immutable, so if the configured ClusterRole changed it needs to be recreated.
+	existing := &rbacv1.RoleBinding{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(roleBinding), existing); err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && existing.RoleRef != roleRef {
+		if err := r.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, roleBinding, func() error {
+		roleBinding.Labels = sandboxLabels(sandbox)
+		roleBinding.RoleRef = roleRef
+		roleBinding.Subjects = ownerSubjects(sandbox)
+
+		return ctrl.SetControllerReference(sandbox, roleBinding, r.Scheme)
+	})
+
+	return err
+}
+
+// ownerSubjects returns the RBAC subjects for the User and Groups of the Sandbox.
+func ownerSubjects(sandbox *devopsv1.Sandbox) []rbacv1.Subject {
+	subjects := []rbacv1.Subject{
+		{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.UserKind,
+			Name:     sandbox.Spec.User,
+		},
+	}
+
+	for _, group := range sandbox.Spec.Groups {
+		subjects = append(subjects, rbacv1.Subject{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.GroupKind,
+			Name:     group,
+		})
+	}
+
+	return subjects
+}
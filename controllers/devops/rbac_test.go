@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestReconcileRoleBinding(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	key := types.NamespacedName{Namespace: "sandbox-jdoe-test-1", Name: roleBindingName}
+
+	// The RoleBinding is created for the owner and controlled by the Sandbox
+	assert.NilError(t, r.reconcileRoleBinding(ctx, sandbox, key.Namespace))
+
+	roleBinding := &rbacv1.RoleBinding{}
+	assert.NilError(t, r.Get(ctx, key, roleBinding))
+	assert.Equal(t, roleBinding.RoleRef, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"})
+	assert.DeepEqual(t, roleBinding.Subjects, []rbacv1.Subject{
+		{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "jdoe"},
+	})
+	assert.DeepEqual(t, roleBinding.Labels, sandboxLabels(sandbox))
+	assert.Assert(t, metav1.IsControlledBy(roleBinding, sandbox))
+
+	// Groups that are added to the Sandbox are added as subjects
+	sandbox.Spec.Groups = []string{"devops"}
+	assert.NilError(t, r.reconcileRoleBinding(ctx, sandbox, key.Namespace))
+
+	assert.NilError(t, r.Get(ctx, key, roleBinding))
+	assert.DeepEqual(t, roleBinding.Subjects, []rbacv1.Subject{
+		{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "jdoe"},
+		{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "devops"},
+	})
+
+	// The RoleRef is immutable, so a change of the ClusterRole recreates the RoleBinding
+	r.Config.OwnerClusterRole = "edit"
+	assert.NilError(t, r.reconcileRoleBinding(ctx, sandbox, key.Namespace))
+
+	assert.NilError(t, r.Get(ctx, key, roleBinding))
+	assert.Equal(t, roleBinding.RoleRef.Name, "edit")
+	assert.Assert(t, metav1.IsControlledBy(roleBinding, sandbox))
+}
+
+func TestReconcileRoleBindingOwnerReference(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	other := &devopsv1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "test-2", UID: "5678"}}
+
+	var tests = map[string]struct {
+		owner   *devopsv1.Sandbox
+		adopted bool
+	}{
+		"Existing RoleBinding without owner is adopted":     {nil, true},
+		"RoleBinding controlled by another Sandbox is kept": {other, false},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			existing := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "sandbox-jdoe-test-1", Name: roleBindingName},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+			}
+			r := newTestReconciler(t, sandbox)
+			if data.owner != nil {
+				assert.NilError(t, controllerutil.SetControllerReference(data.owner, existing, r.Scheme))
+			}
+			assert.NilError(t, r.Create(ctx, existing))
+
+			err := r.reconcileRoleBinding(ctx, sandbox, existing.Namespace)
+
+			roleBinding := &rbacv1.RoleBinding{}
+			assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: existing.Namespace, Name: roleBindingName}, roleBinding))
+			if data.adopted {
+				assert.NilError(t, err)
+				assert.Assert(t, metav1.IsControlledBy(roleBinding, sandbox))
+			} else {
+				assert.ErrorContains(t, err, "already owned")
+				assert.Assert(t, metav1.IsControlledBy(roleBinding, other))
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	resourceQuotaName = "sandbox-quota"
+	limitRangeName    = "sandbox-limits"
+)
+
+// +kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileResourceQuota creates or updates the ResourceQuota that enforces the resource budget of the Sandbox.
+func (r *SandboxReconciler) reconcileResourceQuota(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) error {
+	resources := r.effectiveResources(sandbox)
+
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceQuotaName,
+			Namespace: namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		quota.Labels = sandboxLabels(sandbox)
+		quota.Spec.Hard = corev1.ResourceList{
+			corev1.ResourceRequestsCPU:            *resources.CPU,
+			corev1.ResourceLimitsCPU:              *resources.CPU,
+			corev1.ResourceRequestsMemory:         *resources.Memory,
+			corev1.ResourceLimitsMemory:           *resources.Memory,
+			corev1.ResourceRequestsStorage:        *resources.Storage,
+			corev1.ResourcePods:                   *resource.NewQuantity(*resources.Pods, resource.DecimalSI),
+			corev1.ResourceServices:               *resource.NewQuantity(*resources.Services, resource.DecimalSI),
+			corev1.ResourcePersistentVolumeClaims: *resource.NewQuantity(*resources.PersistentVolumeClaims, resource.DecimalSI),
+		}
+
+		return ctrl.SetControllerReference(sandbox, quota, r.Scheme)
+	})
+
+	return err
+}
+
+// reconcileLimitRange creates or updates the LimitRange that gives containers default requests and limits,
+// which is needed as the ResourceQuota requires every container to declare them.
+func (r *SandboxReconciler) reconcileLimitRange(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) error {
+	resources := r.effectiveResources(sandbox)
+
+	limitRange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      limitRangeName,
+			Namespace: namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, limitRange, func() error {
+		limitRange.Labels = sandboxLabels(sandbox)
+		limitRange.Spec.Limits = []corev1.LimitRangeItem{
+			{
+				Type:           corev1.LimitTypeContainer,
+				DefaultRequest: resources.DefaultRequest,
+				Default:        resources.DefaultLimit,
+			},
+		}
+
+		return ctrl.SetControllerReference(sandbox, limitRange, r.Scheme)
+	})
+
+	return err
+}
+
+// effectiveResources returns the resource budget of the Sandbox, with every field that is left empty
+// in the spec filled in from the operator-wide defaults.
+func (r *SandboxReconciler) effectiveResources(sandbox *devopsv1.Sandbox) *devopsv1.SandboxResources {
+	resources := &devopsv1.SandboxResources{}
+	if sandbox.Spec.Resources != nil {
+		resources = sandbox.Spec.Resources.DeepCopy()
+	}
+
+	if resources.CPU == nil {
+		resources.CPU = r.Config.DefaultCPU.Copy()
+	}
+	if resources.Memory == nil {
+		resources.Memory = r.Config.DefaultMemory.Copy()
+	}
+	if resources.Storage == nil {
+		resources.Storage = r.Config.DefaultStorage.Copy()
+	}
+	if resources.Pods == nil {
+		resources.Pods = int64Ptr(r.Config.DefaultPods)
+	}
+	if resources.Services == nil {
+		resources.Services = int64Ptr(r.Config.DefaultServices)
+	}
+	if resources.PersistentVolumeClaims == nil {
+		resources.PersistentVolumeClaims = int64Ptr(r.Config.DefaultPersistentVolumeClaims)
+	}
+
+	resources.DefaultRequest = withDefaults(resources.DefaultRequest, corev1.ResourceList{
+		corev1.ResourceCPU:    r.Config.DefaultContainerCPURequest.Quantity,
+		corev1.ResourceMemory: r.Config.DefaultContainerMemoryRequest.Quantity,
+	})
+	resources.DefaultLimit = withDefaults(resources.DefaultLimit, corev1.ResourceList{
+		corev1.ResourceCPU:    r.Config.DefaultContainerCPULimit.Quantity,
+		corev1.ResourceMemory: r.Config.DefaultContainerMemoryLimit.Quantity,
+	})
+
+	return resources
+}
+
+// withDefaults returns a copy of list with all resources that are missing taken from defaults.
+func withDefaults(list corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for name, quantity := range defaults {
+		result[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range list {
+		result[name] = quantity.DeepCopy()
+	}
+
+	return result
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
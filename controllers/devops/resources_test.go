@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestEffectiveResources(t *testing.T) {
+	reconciler := &SandboxReconciler{
+		Config: &Config{
+			DefaultCPU:                    quantity("4"),
+			DefaultMemory:                 quantity("8Gi"),
+			DefaultStorage:                quantity("50Gi"),
+			DefaultPods:                   50,
+			DefaultServices:               20,
+			DefaultPersistentVolumeClaims: 10,
+			DefaultContainerCPURequest:    quantity("100m"),
+			DefaultContainerMemoryRequest: quantity("128Mi"),
+			DefaultContainerCPULimit:      quantity("500m"),
+			DefaultContainerMemoryLimit:   quantity("512Mi"),
+		},
+	}
+
+	t.Run("Defaults if no resources given", func(t *testing.T) {
+		resources := reconciler.effectiveResources(&devopsv1.Sandbox{})
+		assert.Equal(t, resources.CPU.String(), "4")
+		assert.Equal(t, resources.Memory.String(), "8Gi")
+		assert.Equal(t, resources.Storage.String(), "50Gi")
+		assert.Equal(t, *resources.Pods, int64(50))
+		assert.Equal(t, *resources.Services, int64(20))
+		assert.Equal(t, *resources.PersistentVolumeClaims, int64(10))
+		assert.Equal(t, resources.DefaultRequest.Cpu().String(), "100m")
+		assert.Equal(t, resources.DefaultLimit.Memory().String(), "512Mi")
+	})
+
+	t.Run("Spec overrides defaults", func(t *testing.T) {
+		cpu := resource.MustParse("2")
+		pods := int64(5)
+		sandbox := &devopsv1.Sandbox{
+			Spec: devopsv1.SandboxSpec{
+				Resources: &devopsv1.SandboxResources{
+					CPU:  &cpu,
+					Pods: &pods,
+					DefaultLimit: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+			},
+		}
+
+		resources := reconciler.effectiveResources(sandbox)
+		assert.Equal(t, resources.CPU.String(), "2")
+		assert.Equal(t, resources.Memory.String(), "8Gi")
+		assert.Equal(t, *resources.Pods, int64(5))
+		assert.Equal(t, resources.DefaultLimit.Cpu().String(), "1")
+		assert.Equal(t, resources.DefaultLimit.Memory().String(), "512Mi")
+	})
+}
+
+func quantity(value string) Quantity {
+	return Quantity{resource.MustParse(value)}
+}
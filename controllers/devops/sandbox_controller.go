@@ -19,18 +19,25 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/stackvista/sandbox-operator/internal/clock"
 	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
 )
@@ -38,12 +45,17 @@ import (
 // SandboxReconciler reconciles a Sandbox object
 type SandboxReconciler struct {
 	client.Client
+	// Reader lists the workloads in the sandbox namespaces directly from the API server, so that they do not need to be
+	// cached for the whole cluster.
+	Reader client.Reader
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	Config *Config
 }
 
 // +kubebuilder:rbac:groups=devops.stackstate.com,resources=sandboxes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=devops.stackstate.com,resources=sandboxes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 
 func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("sandbox", req.NamespacedName)
@@ -53,7 +65,7 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Cleanup is performed by the teardown before the finalizer is released.
 			// Return and don't requeue
 			log.Info("Sandbox resource not found. Ignoring since object must be deleted")
 			return ctrl.Result{}, nil
@@ -63,73 +75,318 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	namespaceName := pkgsandbox.SandboxName(sandbox)
+	if !sandbox.DeletionTimestamp.IsZero() {
+		return r.teardown(ctx, sandbox)
+	}
+
+	if !controllerutil.ContainsFinalizer(sandbox, teardownFinalizer) {
+		controllerutil.AddFinalizer(sandbox, teardownFinalizer)
+		if err := r.Update(ctx, sandbox); err != nil {
+			log.Error(err, "Unable to add finalizer to Sandbox")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// original is the status before reconciling, conditions are changed along the way and written at the end
+	original := sandbox.Status.DeepCopy()
+
+	template, err := r.findTemplate(ctx, sandbox)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get SandboxTemplate")
+		return ctrl.Result{}, err
+	}
+
+	// A missing template does not block provisioning, it is reported in the status and the Sandbox is requeued with
+	// backoff. Its objects are applied once it is created, which is also observed by the watch.
+	templateMissing := errors.IsNotFound(err)
+	if sandbox.Spec.TemplateRef == nil {
+		pkgsandbox.RemoveCondition(sandbox, devopsv1.ConditionTemplateReady)
+	} else if templateMissing {
+		log.Info("SandboxTemplate not found, provisioning without it", "template", sandbox.Spec.TemplateRef.Name)
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionTemplateReady, metav1.ConditionFalse, "TemplateNotFound",
+			fmt.Sprintf("SandboxTemplate %s does not exist", sandbox.Spec.TemplateRef.Name), clock.Ctx(ctx).Now())
+	}
+
+	// desired is the Sandbox with the defaults of its template applied, used to provision the namespace contents.
+	desired := withTemplateDefaults(sandbox, template)
+
+	namespaceNames := pkgsandbox.NamespaceNames(sandbox)
+
+	namespaces := []*corev1.Namespace{}
+	conflicts := []string{}
+	for _, name := range namespaceNames {
+		ns, conflict, err := r.reconcileNamespace(ctx, sandbox, name)
+		if err != nil {
+			log.Error(err, "Error reconciling Namespace", "namespace", name)
+			return ctrl.Result{}, err
+		}
+
+		if conflict != "" {
+			conflicts = append(conflicts, conflict)
+			continue
+		}
 
-	ns, err := r.findNamespace(ctx, namespaceName)
+		namespaces = append(namespaces, ns)
+	}
+
+	if len(conflicts) > 0 {
+		// Retrying does not resolve the conflict, so it is only reported in the status
+		log.Info("Namespace exists, but is not owned by sandbox", "reason", conflicts)
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionConflict, metav1.ConditionTrue, "NamespaceExists",
+			strings.Join(conflicts, "; "), clock.Ctx(ctx).Now())
+		if err := r.updateStatus(ctx, sandbox, original, namespaces); err != nil {
+			log.Error(err, "Unable to update sandbox status")
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, nil
+	}
+	pkgsandbox.RemoveCondition(sandbox, devopsv1.ConditionConflict)
+
+	for _, ns := range namespaces {
+		if !ns.DeletionTimestamp.IsZero() {
+			// The namespace was deleted out-of-band, it is recreated once it is gone, which is observed by the watch.
+			log.Info("Namespace of Sandbox is being deleted, waiting to recreate it", "namespace", ns.Name)
+			if err := r.updateStatus(ctx, sandbox, original, namespaces); err != nil {
+				log.Error(err, "Unable to update sandbox status")
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if err := r.pruneNamespaces(ctx, sandbox, namespaceNames); err != nil {
+		log.Error(err, "Error deleting Namespaces that were removed from the Sandbox")
+		return ctrl.Result{}, err
+	}
+
+	for _, ns := range namespaces {
+		log := log.WithValues("namespace", ns.Name)
+
+		if err := r.reconcileResourceQuota(ctx, desired, ns.Name); err != nil {
+			log.Error(err, "Error reconciling ResourceQuota")
+			return ctrl.Result{}, err
+		}
+
+		if err := r.reconcileLimitRange(ctx, desired, ns.Name); err != nil {
+			log.Error(err, "Error reconciling LimitRange")
+			return ctrl.Result{}, err
+		}
+
+		if err := r.reconcileRoleBinding(ctx, desired, ns.Name); err != nil {
+			log.Error(err, "Error reconciling RoleBinding")
+			setCondition(ctx, sandbox, devopsv1.ConditionRBACReady, "RoleBinding", err)
+			if err := r.updateStatus(ctx, sandbox, original, namespaces); err != nil {
+				log.Error(err, "Unable to update sandbox status")
+			}
+			return ctrl.Result{}, err
+		}
+
+		if err := r.reconcileNetworkPolicy(ctx, desired, ns.Name); err != nil {
+			log.Error(err, "Error reconciling NetworkPolicy")
+			return ctrl.Result{}, err
+		}
+
+		if err := r.reconcileSecrets(ctx, desired, ns.Name); err != nil {
+			log.Error(err, "Error reconciling Secrets")
+			return ctrl.Result{}, err
+		}
+
+		if templateMissing {
+			continue
+		}
+
+		if err := r.reconcileTemplateObjects(ctx, desired, template, ns); err != nil {
+			log.Error(err, "Error reconciling SandboxTemplate objects")
+			if template != nil {
+				setCondition(ctx, sandbox, devopsv1.ConditionTemplateReady, "Template", err)
+				if err := r.updateStatus(ctx, sandbox, original, namespaces); err != nil {
+					log.Error(err, "Unable to update sandbox status")
+				}
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	setCondition(ctx, sandbox, devopsv1.ConditionRBACReady, "RoleBinding", nil)
+	if template != nil {
+		setCondition(ctx, sandbox, devopsv1.ConditionTemplateReady, "Template", nil)
+	}
+
+	result, err := r.reconcileHibernation(ctx, sandbox, namespaces)
 	if err != nil {
+		log.Error(err, "Error reconciling hibernation")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, sandbox, original, namespaces); err != nil {
+		log.Error(err, "Unable to update sandbox status")
 		return ctrl.Result{}, err
 	}
 
+	if templateMissing && result.RequeueAfter == 0 {
+		result.Requeue = true
+	}
+
+	return result, nil
+}
+
+// reconcileNamespace creates the namespace with the given name for the Sandbox, or takes it over if it already
+// exists. If the existing namespace cannot be adopted, the reason is returned.
+func (r *SandboxReconciler) reconcileNamespace(ctx context.Context, sandbox *devopsv1.Sandbox, name string) (*corev1.Namespace, string, error) {
+	log := r.Log.WithValues("sandbox", sandbox.Name, "namespace", name)
+
+	ns, err := r.findNamespace(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+
 	if ns == nil {
 		log.Info("Provisioning Namespace for Sandbox")
-		newNs := &corev1.Namespace{
+		ns = &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: namespaceName,
-				Labels: map[string]string{
-					"sandboxer/created-by": sandbox.Spec.User,
-				},
+				Name:        name,
+				Labels:      sandboxLabels(sandbox),
+				Annotations: namespaceAnnotations(sandbox),
 			},
 		}
 
-		if err := ctrl.SetControllerReference(sandbox, newNs, r.Scheme); err != nil {
-			log.Error(err, "Error setting controller reference to sandbox")
-			return ctrl.Result{}, err
+		if err := ctrl.SetControllerReference(sandbox, ns, r.Scheme); err != nil {
+			return nil, "", err
 		}
 
-		if err := r.Create(ctx, newNs, &client.CreateOptions{}); err != nil {
-			log.Error(err, "Error creating new Namespace")
-			return ctrl.Result{}, err
+		if err := r.Create(ctx, ns, &client.CreateOptions{}); err != nil {
+			return nil, "", err
+		}
+
+		return ns, "", nil
+	}
+
+	if !metav1.IsControlledBy(ns, sandbox) {
+		if ok, reason := canAdopt(sandbox, ns); !ok {
+			return nil, reason, nil
+		}
+
+		log.Info("Adopting existing Namespace")
+		if err := r.adoptNamespace(ctx, sandbox, ns); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if ns.DeletionTimestamp.IsZero() {
+		if err := r.restoreNamespaceMetadata(ctx, sandbox, ns); err != nil {
+			return nil, "", err
 		}
+	}
 
-		log.WithValues("status.phase", newNs.Status.Phase).Info("Created namespace is now...")
-		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, sandbox.DeepCopy(), func() error {
-			sandbox.Status.NamespaceStatus = newNs.Status
-			return r.Client.Status().Update(ctx, sandbox, &client.UpdateOptions{})
-		})
+	return ns, "", nil
+}
+
+// pruneNamespaces deletes the namespaces that were provisioned for the Sandbox, but are no longer part of it. This is
+// only done when the Sandbox is annotated to allow it, the namespaces are otherwise kept until the Sandbox is deleted.
+func (r *SandboxReconciler) pruneNamespaces(ctx context.Context, sandbox *devopsv1.Sandbox, names []string) error {
+	prune := sandbox.Annotations[devopsv1.PruneNamespacesAnnotation] == "true"
+
+	for _, status := range sandbox.Status.Namespaces {
+		if containsString(names, status.Name) {
+			continue
+		}
+
+		ns, err := r.findNamespace(ctx, status.Name)
 		if err != nil {
-			log.Error(err, "Unable to update sandbox status")
-			return ctrl.Result{}, err
+			return err
 		}
-	} else {
-		if sandbox.Status.NamespaceStatus.Phase == v1.NamespaceActive {
-			return ctrl.Result{}, nil
+
+		if ns == nil || !metav1.IsControlledBy(ns, sandbox) || !ns.DeletionTimestamp.IsZero() {
+			continue
 		}
 
-		log.WithValues("status.phase", sandbox.Status.NamespaceStatus.Phase).Info("Namespace exists, but sandbox status is not Active")
+		log := r.Log.WithValues("sandbox", sandbox.Name, "namespace", ns.Name)
+		if !prune {
+			log.Info("Keeping Namespace that was removed from Sandbox, as pruning is not allowed", "annotation", devopsv1.PruneNamespacesAnnotation)
+			continue
+		}
 
-		return ctrl.Result{}, fmt.Errorf("Namespace for sandbox already exists")
+		log.Info("Deleting Namespace that was removed from Sandbox")
+		if err := r.Delete(ctx, ns); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
 	}
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
+// findNamespace gets the namespace with the given name from the cache, it returns nil if it does not exist.
 func (r *SandboxReconciler) findNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
-	namespaces := &corev1.NamespaceList{}
-	if err := r.List(ctx, namespaces, &client.ListOptions{}); err != nil {
-		return nil, err
-	}
-
-	for _, ns := range namespaces.Items {
-		if ns.Name == name {
-			return ns.DeepCopy(), nil
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	return nil, nil
+	return ns, nil
 }
 
 func (r *SandboxReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	copiedSecrets, sourceSecrets, err := r.secretSources(mgr)
+	if err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&devopsv1.Sandbox{}).
-		Complete(r)
+		Owns(&corev1.Namespace{}).
+		Owns(&corev1.ResourceQuota{}).
+		Owns(&corev1.LimitRange{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&batchv1.Job{}).
+		Watches(&source.Kind{Type: &devopsv1.SandboxTemplate{}}, handler.EnqueueRequestsFromMapFunc(r.sandboxesForTemplate)).
+		Watches(copiedSecrets, &handler.EnqueueRequestForOwner{OwnerType: &devopsv1.Sandbox{}, IsController: true})
+	for _, src := range sourceSecrets {
+		builder = builder.Watches(src, handler.EnqueueRequestsFromMapFunc(r.sandboxesForSecret))
+	}
+
+	return builder.Complete(r)
+}
+
+// sandboxLabels returns the labels that are put on every object created for the Sandbox.
+func sandboxLabels(sandbox *devopsv1.Sandbox) map[string]string {
+	return map[string]string{
+		"sandboxer/created-by": sandbox.Spec.User,
+	}
+}
+
+// namespaceAnnotations returns the annotations that are put on the namespace of the Sandbox.
+func namespaceAnnotations(sandbox *devopsv1.Sandbox) map[string]string {
+	return map[string]string{
+		"sandboxer/sandbox": sandbox.Name,
+	}
+}
+
+// restoreNamespaceMetadata puts back the managed labels and annotations of the namespace if they were changed.
+func (r *SandboxReconciler) restoreNamespaceMetadata(ctx context.Context, sandbox *devopsv1.Sandbox, ns *corev1.Namespace) error {
+	labels := sandboxLabels(sandbox)
+	annotations := namespaceAnnotations(sandbox)
+	if containsAll(ns.Labels, labels) && containsAll(ns.Annotations, annotations) {
+		return nil
+	}
+
+	r.Log.WithValues("sandbox", sandbox.Name).Info("Restoring labels and annotations of Namespace")
+	ns.Labels = mergeMaps(ns.Labels, labels)
+	ns.Annotations = mergeMaps(ns.Annotations, annotations)
+
+	return r.Update(ctx, ns)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileNamespaceDrift(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+	key := types.NamespacedName{Name: "sandbox-jdoe-test-1"}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	// Managed labels and annotations are restored
+	ns := &corev1.Namespace{}
+	assert.NilError(t, r.Get(ctx, key, ns))
+	ns.Labels = map[string]string{"team": "devops"}
+	ns.Annotations = nil
+	assert.NilError(t, r.Update(ctx, ns))
+
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	assert.NilError(t, r.Get(ctx, key, ns))
+	assert.DeepEqual(t, ns.Labels, map[string]string{"team": "devops", "sandboxer/created-by": "jdoe"})
+	assert.DeepEqual(t, ns.Annotations, map[string]string{"sandboxer/sandbox": "test-1"})
+
+	// A namespace deleted out-of-band is recreated
+	assert.NilError(t, r.Delete(ctx, ns))
+
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	assert.NilError(t, r.Get(ctx, key, &corev1.Namespace{}))
+	assert.Equal(t, getSandbox(t, r, sandbox.Name).Status.Namespace, key.Name)
+}
+
+func TestReconcileMultipleNamespaces(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe", Namespaces: []string{"app", "data"}},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	for _, name := range []string{"sandbox-jdoe-test-1-app", "sandbox-jdoe-test-1-data"} {
+		assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: name}, &corev1.Namespace{}))
+		assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: name, Name: "sandbox-owner"}, &rbacv1.RoleBinding{}))
+	}
+
+	updated := getSandbox(t, r, sandbox.Name)
+	assert.Equal(t, updated.Status.Namespace, "sandbox-jdoe-test-1-app")
+	assert.Equal(t, len(updated.Status.Namespaces), 2)
+
+	// Namespaces that are removed from the spec are kept, unless pruning is allowed
+	updated.Spec.Namespaces = []string{"app"}
+	assert.NilError(t, r.Update(ctx, updated))
+
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: "sandbox-jdoe-test-1-data"}, &corev1.Namespace{}))
+
+	updated = getSandbox(t, r, sandbox.Name)
+	updated.Spec.Namespaces = []string{"app", "data"}
+	assert.NilError(t, r.Update(ctx, updated))
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	updated = getSandbox(t, r, sandbox.Name)
+	updated.Annotations = map[string]string{devopsv1.PruneNamespacesAnnotation: "true"}
+	updated.Spec.Namespaces = []string{"app"}
+	assert.NilError(t, r.Update(ctx, updated))
+
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+
+	err = r.Get(ctx, types.NamespacedName{Name: "sandbox-jdoe-test-1-data"}, &corev1.Namespace{})
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.DeepEqual(t, getSandbox(t, r, sandbox.Name).Status.Namespaces,
+		[]devopsv1.SandboxNamespaceStatus{{Name: "sandbox-jdoe-test-1-app"}})
+}
+
+// countingClient counts the reads per type, and the namespaces returned by List calls, which is the part of the
+// reconcile cost that would grow with the size of the cluster.
+type countingClient struct {
+	client.Client
+	calls map[string]int
+}
+
+func (c *countingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.calls[fmt.Sprintf("get %T", obj)]++
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *countingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.calls[fmt.Sprintf("list %T", list)]++
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	if namespaces, ok := list.(*corev1.NamespaceList); ok {
+		c.calls["listed namespaces"] += len(namespaces.Items)
+	}
+	return nil
+}
+
+func (c *countingClient) total() int {
+	total := 0
+	for _, n := range c.calls {
+		total += n
+	}
+
+	return total
+}
+
+func TestReconcileCostIsIndependentOfNamespaceCount(t *testing.T) {
+	_, baseline := reconcileCalls(t, 1)
+	assert.Assert(t, baseline.calls["get *v1.Namespace"] > 0, baseline.calls)
+	assert.Equal(t, baseline.calls["listed namespaces"], 0, baseline.calls)
+
+	for _, count := range []int{10, 1000} {
+		t.Run(fmt.Sprintf("%d namespaces", count), func(t *testing.T) {
+			_, c := reconcileCalls(t, count)
+			assert.DeepEqual(t, c.calls, baseline.calls)
+		})
+	}
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	_, baseline := reconcileCalls(b, 1)
+
+	for _, count := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d namespaces", count), func(b *testing.B) {
+			r, c := reconcileCalls(b, count)
+			c.calls = map[string]int{}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-1"}}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.Reconcile(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(c.total())/float64(b.N), "reads/op")
+			if c.total() != baseline.total()*b.N {
+				b.Fatalf("expected %d reads per reconcile, as with a single namespace, got %v in %d reconciles",
+					baseline.total(), c.calls, b.N)
+			}
+		})
+	}
+}
+
+// reconcileCalls reconciles a provisioned Sandbox in a cluster with count other namespaces, and returns the reads of
+// that reconcile.
+func reconcileCalls(t testing.TB, count int) (*SandboxReconciler, *countingClient) {
+	r, c := newReconcilerWithNamespaces(t, count)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-1"}}
+
+	// The first reconcile provisions the Sandbox, the second one is the steady state that is measured
+	_, err := r.Reconcile(context.Background(), req)
+	assert.NilError(t, err)
+
+	c.calls = map[string]int{}
+	_, err = r.Reconcile(context.Background(), req)
+	assert.NilError(t, err)
+
+	return r, c
+}
+
+// newReconcilerWithNamespaces returns a reconciler for a single Sandbox in a cluster with count other namespaces.
+func newReconcilerWithNamespaces(t testing.TB, count int) (*SandboxReconciler, *countingClient) {
+	objs := []client.Object{
+		&devopsv1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+			Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+		},
+	}
+	for i := 0; i < count; i++ {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("namespace-%d", i)}})
+	}
+
+	r := newTestReconciler(t, objs...)
+	c := &countingClient{Client: r.Client, calls: map[string]int{}}
+	r.Client = c
+	r.Reader = c
+
+	return r, c
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// copiedSecretLabel marks secrets that are copied into a sandbox by the operator.
+	copiedSecretLabel = "sandboxer/copied-secret"
+	// sourceSecretAnnotation records the namespace/name of the secret a copy was made from.
+	sourceSecretAnnotation = "sandboxer/source-secret"
+
+	defaultServiceAccountName = "default"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileSecrets copies the operator-wide and Sandbox specific secrets into the sandbox namespace, removes copies
+// that are no longer wanted and attaches the image pull secrets to the default ServiceAccount.
+func (r *SandboxReconciler) reconcileSecrets(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) error {
+	copied := map[string]bool{}
+	pullSecrets := []string{}
+
+	for _, ref := range r.secretReferences(sandbox) {
+		source := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, source); err != nil {
+			return fmt.Errorf("unable to get secret %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+
+		if err := r.copySecret(ctx, sandbox, source, namespace); err != nil {
+			return fmt.Errorf("unable to copy secret %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+
+		copied[source.Name] = true
+		if source.Type == corev1.SecretTypeDockerConfigJson || source.Type == corev1.SecretTypeDockercfg {
+			pullSecrets = append(pullSecrets, source.Name)
+		}
+	}
+
+	removed, err := r.pruneSecrets(ctx, namespace, copied)
+	if err != nil {
+		return err
+	}
+
+	return r.reconcileImagePullSecrets(ctx, namespace, pullSecrets, removed)
+}
+
+// secretReferences returns the operator-wide shared secrets followed by the secrets requested by the Sandbox. Requested
+// secrets that are not allowed by the operator are skipped, as are secrets with the name of an earlier one, because the
+// copies are named after their source.
+func (r *SandboxReconciler) secretReferences(sandbox *devopsv1.Sandbox) []corev1.SecretReference {
+	refs := []corev1.SecretReference{}
+	names := map[string]bool{}
+	for _, ref := range r.Config.SharedSecrets {
+		if !names[ref.Name] {
+			names[ref.Name] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, ref := range sandbox.Spec.Secrets {
+		log := r.Log.WithValues("sandbox", sandbox.Name, "secret", fmt.Sprintf("%s/%s", ref.Namespace, ref.Name))
+		if !pkgsandbox.ContainsSecretReference(r.Config.AllowedSecrets, ref) {
+			log.Info("Not copying secret that is not allowed")
+			continue
+		}
+		if names[ref.Name] {
+			log.Info("Not copying secret with the same name as another secret")
+			continue
+		}
+
+		names[ref.Name] = true
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// copySecret creates or updates the copy of the source secret in the sandbox namespace.
+func (r *SandboxReconciler) copySecret(ctx context.Context, sandbox *devopsv1.Sandbox, source *corev1.Secret, namespace string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = mergeMaps(secret.Labels, sandboxLabels(sandbox), map[string]string{copiedSecretLabel: "true"})
+		secret.Annotations = mergeMaps(secret.Annotations, map[string]string{
+			sourceSecretAnnotation: fmt.Sprintf("%s/%s", source.Namespace, source.Name),
+		})
+		secret.Type = source.Type
+		secret.Data = source.Data
+
+		return ctrl.SetControllerReference(sandbox, secret, r.Scheme)
+	})
+
+	return err
+}
+
+// pruneSecrets deletes the copied secrets in the namespace that are not in the wanted set, and returns their names.
+func (r *SandboxReconciler) pruneSecrets(ctx context.Context, namespace string, wanted map[string]bool) ([]string, error) {
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(namespace), client.MatchingLabels{copiedSecretLabel: "true"}); err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if wanted[secret.Name] {
+			continue
+		}
+
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		removed = append(removed, secret.Name)
+	}
+
+	return removed, nil
+}
+
+// reconcileImagePullSecrets makes sure the default ServiceAccount of the namespace references all copied image
+// pull secrets, and no longer references the removed ones.
+func (r *SandboxReconciler) reconcileImagePullSecrets(ctx context.Context, namespace string, pullSecrets []string, removed []string) error {
+	if len(pullSecrets) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultServiceAccountName,
+			Namespace: namespace,
+		},
+	}
+
+	// The default ServiceAccount may not have been created yet by Kubernetes, in which case we create it.
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		isRemoved := map[string]bool{}
+		for _, name := range removed {
+			isRemoved[name] = true
+		}
+
+		present := map[string]bool{}
+		refs := []corev1.LocalObjectReference{}
+		for _, ref := range sa.ImagePullSecrets {
+			if isRemoved[ref.Name] {
+				continue
+			}
+			present[ref.Name] = true
+			refs = append(refs, ref)
+		}
+
+		for _, name := range pullSecrets {
+			if !present[name] {
+				refs = append(refs, corev1.LocalObjectReference{Name: name})
+			}
+		}
+
+		sa.ImagePullSecrets = refs
+		return nil
+	})
+
+	return err
+}
+
+// secretSources returns the sources of the copied secrets and of the secrets they are copied from. Secrets are not
+// cached by the manager, so that the operator does not keep every Secret of the cluster in memory. Instead the copies
+// are watched by their label, and each shared and allowed secret is watched by its name.
+func (r *SandboxReconciler) secretSources(mgr ctrl.Manager) (copies source.Source, sources []source.Source, err error) {
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newInformer := func(namespace string, tweak func(*metav1.ListOptions)) (source.Source, error) {
+		lw := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				tweak(&options)
+				return clientset.CoreV1().Secrets(namespace).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				tweak(&options)
+				return clientset.CoreV1().Secrets(namespace).Watch(context.Background(), options)
+			},
+		}
+
+		informer := cache.NewSharedIndexInformer(lw, &corev1.Secret{}, 0, cache.Indexers{})
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			informer.Run(ctx.Done())
+			return nil
+		})); err != nil {
+			return nil, err
+		}
+
+		return &source.Informer{Informer: informer}, nil
+	}
+
+	copies, err = newInformer(metav1.NamespaceAll, func(options *metav1.ListOptions) {
+		options.LabelSelector = labels.SelectorFromSet(labels.Set{copiedSecretLabel: "true"}).String()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	watched := []corev1.SecretReference{}
+	for _, ref := range append(append([]corev1.SecretReference{}, r.Config.SharedSecrets...), r.Config.AllowedSecrets...) {
+		if pkgsandbox.ContainsSecretReference(watched, ref) {
+			continue
+		}
+		watched = append(watched, ref)
+
+		name := ref.Name
+		src, err := newInformer(ref.Namespace, func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, src)
+	}
+
+	return copies, sources, nil
+}
+
+// sandboxesForSecret maps a source secret to reconcile requests for all Sandboxes it is copied to.
+func (r *SandboxReconciler) sandboxesForSecret(obj client.Object) []reconcile.Request {
+	ref := corev1.SecretReference{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	isShared := pkgsandbox.ContainsSecretReference(r.Config.SharedSecrets, ref)
+
+	sandboxes := &devopsv1.SandboxList{}
+	if err := r.List(context.Background(), sandboxes); err != nil {
+		r.Log.Error(err, "Unable to list sandboxes for secret", "secret", obj.GetNamespace()+"/"+obj.GetName())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, sb := range sandboxes.Items {
+		if isShared || pkgsandbox.ContainsSecretReference(sb.Spec.Secrets, ref) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: sb.Name}})
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileSecrets(t *testing.T) {
+	ctx := context.Background()
+	namespace := "sandbox-jdoe-test-1"
+	registry := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "registry"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte("{}")},
+	}
+	tls := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec: devopsv1.SandboxSpec{
+			User:    "jdoe",
+			Secrets: []corev1.SecretReference{{Namespace: "infra", Name: "tls"}},
+		},
+	}
+
+	r := newTestReconciler(t, sandbox, registry, tls)
+	r.Config.SharedSecrets = pkgsandbox.SecretReferences{{Namespace: "infra", Name: "registry"}}
+	r.Config.AllowedSecrets = pkgsandbox.SecretReferences{{Namespace: "infra", Name: "tls"}}
+
+	// The shared and the requested secrets are copied, and the image pull secret is added to the default ServiceAccount
+	assert.NilError(t, r.reconcileSecrets(ctx, sandbox, namespace))
+
+	for _, source := range []*corev1.Secret{registry, tls} {
+		secret := &corev1.Secret{}
+		assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: source.Name}, secret))
+		assert.Equal(t, secret.Type, source.Type)
+		assert.DeepEqual(t, secret.Data, source.Data)
+		assert.Equal(t, secret.Labels[copiedSecretLabel], "true")
+		assert.Equal(t, secret.Annotations[sourceSecretAnnotation], "infra/"+source.Name)
+		assert.Assert(t, metav1.IsControlledBy(secret, sandbox))
+	}
+	assert.DeepEqual(t, imagePullSecrets(t, r, namespace), []string{"registry"})
+
+	// Secrets that are no longer wanted are deleted, and removed from the default ServiceAccount
+	r.Config.SharedSecrets = nil
+	assert.NilError(t, r.reconcileSecrets(ctx, sandbox, namespace))
+
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "registry"}, &corev1.Secret{})
+	assert.Assert(t, errors.IsNotFound(err), err)
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "tls"}, &corev1.Secret{}))
+	assert.DeepEqual(t, imagePullSecrets(t, r, namespace), []string{})
+}
+
+func TestReconcileSecretsKeepsUnmanagedImagePullSecrets(t *testing.T) {
+	ctx := context.Background()
+	namespace := "sandbox-jdoe-test-1"
+	registry := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "registry"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+	}
+	sa := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Namespace: namespace, Name: defaultServiceAccountName},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "own-registry"}},
+	}
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	r := newTestReconciler(t, sandbox, registry, sa)
+	r.Config.SharedSecrets = pkgsandbox.SecretReferences{{Namespace: "infra", Name: "registry"}}
+
+	assert.NilError(t, r.reconcileSecrets(ctx, sandbox, namespace))
+	assert.DeepEqual(t, imagePullSecrets(t, r, namespace), []string{"own-registry", "registry"})
+
+	r.Config.SharedSecrets = nil
+	assert.NilError(t, r.reconcileSecrets(ctx, sandbox, namespace))
+	assert.DeepEqual(t, imagePullSecrets(t, r, namespace), []string{"own-registry"})
+}
+
+func TestSecretReferences(t *testing.T) {
+	shared := corev1.SecretReference{Namespace: "infra", Name: "registry"}
+	allowed := corev1.SecretReference{Namespace: "infra", Name: "tls"}
+	otherTLS := corev1.SecretReference{Namespace: "team", Name: "tls"}
+
+	var tests = map[string]struct {
+		requested []corev1.SecretReference
+		expected  []corev1.SecretReference
+	}{
+		"Only shared secrets":              {nil, []corev1.SecretReference{shared}},
+		"Allowed secret":                   {[]corev1.SecretReference{allowed}, []corev1.SecretReference{shared, allowed}},
+		"Secret that is not allowed":       {[]corev1.SecretReference{{Namespace: "kube-system", Name: "admin-token"}}, []corev1.SecretReference{shared}},
+		"Same name as a shared secret":     {[]corev1.SecretReference{{Namespace: "team", Name: "registry"}}, []corev1.SecretReference{shared}},
+		"Same name as a requested secret":  {[]corev1.SecretReference{allowed, otherTLS}, []corev1.SecretReference{shared, allowed}},
+		"Same secret requested repeatedly": {[]corev1.SecretReference{allowed, allowed}, []corev1.SecretReference{shared, allowed}},
+	}
+
+	r := newTestReconciler(t)
+	r.Config.SharedSecrets = pkgsandbox.SecretReferences{shared}
+	r.Config.AllowedSecrets = pkgsandbox.SecretReferences{allowed, otherTLS, {Namespace: "team", Name: "registry"}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+				Spec:       devopsv1.SandboxSpec{User: "jdoe", Secrets: data.requested},
+			}
+
+			assert.DeepEqual(t, r.secretReferences(sandbox), data.expected)
+		})
+	}
+}
+
+func imagePullSecrets(t *testing.T, r *SandboxReconciler, namespace string) []string {
+	sa := &corev1.ServiceAccount{}
+	assert.NilError(t, r.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: defaultServiceAccountName}, sa))
+
+	names := []string{}
+	for _, ref := range sa.ImagePullSecrets {
+		names = append(names, ref.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setCondition sets a condition on the Sandbox, which is True if err is nil and False with the error as message otherwise.
+func setCondition(ctx context.Context, sandbox *devopsv1.Sandbox, conditionType string, reason string, err error) {
+	now := clock.Ctx(ctx).Now()
+	if err != nil {
+		pkgsandbox.SetCondition(sandbox, conditionType, metav1.ConditionFalse, reason+"Failed", err.Error(), now)
+	} else {
+		pkgsandbox.SetCondition(sandbox, conditionType, metav1.ConditionTrue, reason+"Ready", "", now)
+	}
+}
+
+// updateStatus records the observed state of the sandbox namespaces in the status of the Sandbox, and writes the
+// status if it changed from the original status.
+func (r *SandboxReconciler) updateStatus(ctx context.Context, sandbox *devopsv1.Sandbox, original *devopsv1.SandboxStatus, namespaces []*corev1.Namespace) error {
+	now := clock.Ctx(ctx).Now()
+
+	sandbox.Status.ObservedGeneration = sandbox.Generation
+	if sandbox.Spec.ExpirationDate != nil {
+		sandbox.Status.ExpirationDate = sandbox.Spec.ExpirationDate.DeepCopy()
+	}
+
+	if meta.IsStatusConditionTrue(sandbox.Status.Conditions, devopsv1.ConditionConflict) {
+		sandbox.Status.NamespaceStatus = corev1.NamespaceStatus{}
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionNamespaceReady, metav1.ConditionFalse, "NamespaceConflict",
+			"Namespace is not owned by the sandbox", now)
+	} else if len(namespaces) == 0 {
+		sandbox.Status.NamespaceStatus = corev1.NamespaceStatus{}
+		pkgsandbox.SetCondition(sandbox, devopsv1.ConditionNamespaceReady, metav1.ConditionFalse, "NamespaceMissing", "", now)
+	} else {
+		sandbox.Status.Namespace = namespaces[0].Name
+		sandbox.Status.NamespaceStatus = *namespaces[0].Status.DeepCopy()
+		sandbox.Status.Namespaces = []devopsv1.SandboxNamespaceStatus{}
+
+		notActive := []string{}
+		for _, ns := range namespaces {
+			sandbox.Status.Namespaces = append(sandbox.Status.Namespaces, devopsv1.SandboxNamespaceStatus{
+				Name:  ns.Name,
+				Phase: ns.Status.Phase,
+			})
+
+			if ns.Status.Phase != corev1.NamespaceActive {
+				notActive = append(notActive, fmt.Sprintf("%s is in phase %s", ns.Name, ns.Status.Phase))
+			}
+		}
+
+		if len(notActive) == 0 {
+			pkgsandbox.SetCondition(sandbox, devopsv1.ConditionNamespaceReady, metav1.ConditionTrue, "NamespaceActive", "", now)
+		} else {
+			pkgsandbox.SetCondition(sandbox, devopsv1.ConditionNamespaceReady, metav1.ConditionFalse, "NamespaceNotActive",
+				fmt.Sprintf("Namespace %s", strings.Join(notActive, ", ")), now)
+		}
+	}
+
+	pkgsandbox.UpdatePhase(sandbox)
+
+	if equality.Semantic.DeepEqual(original, &sandbox.Status) {
+		return nil
+	}
+
+	return r.Status().Update(ctx, sandbox)
+}
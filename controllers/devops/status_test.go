@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// roleBindingFailingClient fails to read RoleBindings, as if the operator is not allowed to.
+type roleBindingFailingClient struct {
+	client.Client
+}
+
+func (c *roleBindingFailingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*rbacv1.RoleBinding); ok {
+		return errors.NewForbidden(rbacv1.Resource("rolebindings"), key.Name, fmt.Errorf("access denied"))
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestReconcileWritesConditionChanges(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	_, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Assert(t, meta.IsStatusConditionTrue(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionRBACReady))
+
+	// The namespace of the fake client never becomes active, so the phase stays Pending and only the condition changes
+	c := r.Client
+	r.Client = &roleBindingFailingClient{Client: c}
+	_, err = r.Reconcile(ctx, req)
+	assert.ErrorContains(t, err, "access denied")
+
+	r.Client = c
+	assert.Assert(t, meta.IsStatusConditionFalse(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionRBACReady))
+
+	// The recovery is written as well
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Assert(t, meta.IsStatusConditionTrue(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionRBACReady))
+}
+
+func newTestReconciler(t testing.TB, objs ...client.Object) *SandboxReconciler {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NilError(t, devopsv1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &SandboxReconciler{
+		Client: c,
+		Reader: c,
+		Log:    zapr.NewLogger(zap.NewNop()),
+		Scheme: scheme,
+		Config: &Config{
+			DefaultCPU:                    quantity("4"),
+			DefaultMemory:                 quantity("8Gi"),
+			DefaultStorage:                quantity("50Gi"),
+			DefaultContainerCPURequest:    quantity("100m"),
+			DefaultContainerMemoryRequest: quantity("128Mi"),
+			DefaultContainerCPULimit:      quantity("500m"),
+			DefaultContainerMemoryLimit:   quantity("512Mi"),
+			OwnerClusterRole:              "admin",
+			DefaultIsolation:              devopsv1.IsolationShared,
+		},
+	}
+}
+
+func getSandbox(t testing.TB, r *SandboxReconciler, name string) *devopsv1.Sandbox {
+	sandbox := &devopsv1.Sandbox{}
+	assert.NilError(t, r.Get(context.Background(), types.NamespacedName{Name: name}, sandbox))
+	return sandbox
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// teardownFinalizer keeps the Sandbox around until its namespace and leftovers are removed.
+	teardownFinalizer = "devops.stackstate.com/teardown"
+
+	teardownRequeueInterval = 5 * time.Second
+	defaultHookTimeout      = 5 * time.Minute
+)
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;delete
+
+// teardown performs the ordered deletion of a Sandbox: it runs the pre-delete hooks, deletes the namespace, waits for
+// it to vanish and cleans up cluster-scoped leftovers, before releasing the Sandbox by removing the finalizer.
+func (r *SandboxReconciler) teardown(ctx context.Context, sandbox *devopsv1.Sandbox) (ctrl.Result, error) {
+	log := r.Log.WithValues("sandbox", sandbox.Name)
+
+	if !controllerutil.ContainsFinalizer(sandbox, teardownFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	namespaceNames := teardownNamespaceNames(sandbox)
+	namespaces := []*corev1.Namespace{}
+	for _, name := range namespaceNames {
+		ns, err := r.findNamespace(ctx, name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if ns != nil && metav1.IsControlledBy(ns, sandbox) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	if len(namespaces) > 0 {
+		// The pre-delete hooks are run in the primary namespace, as long as it is not being deleted yet
+		if primary := namespaces[0]; primary.Name == namespaceNames[0] && primary.DeletionTimestamp == nil {
+			pending, err := r.runPreDeleteHooks(ctx, sandbox, primary.Name)
+			if err != nil {
+				log.Error(err, "Error running pre-delete hooks")
+				return ctrl.Result{}, err
+			}
+
+			if len(pending) > 0 {
+				log.Info("Waiting for pre-delete hooks", "hooks", pending)
+				return r.teardownInProgress(ctx, sandbox, &devopsv1.SandboxTeardownStatus{
+					Step:    devopsv1.TeardownPreDeleteHooks,
+					Message: fmt.Sprintf("Waiting for pre-delete hooks: %s", strings.Join(pending, ", ")),
+				})
+			}
+		}
+
+		for _, ns := range namespaces {
+			if ns.DeletionTimestamp != nil {
+				continue
+			}
+
+			log.Info("Deleting Namespace of Sandbox", "namespace", ns.Name)
+			if err := r.Delete(ctx, ns); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Error deleting Namespace", "namespace", ns.Name)
+				return ctrl.Result{}, err
+			}
+		}
+
+		return r.teardownInProgress(ctx, sandbox, namespaceTeardownStatus(namespaces))
+	}
+
+	if _, err := r.teardownInProgress(ctx, sandbox, &devopsv1.SandboxTeardownStatus{Step: devopsv1.TeardownCleaningUp}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.cleanupLeftovers(ctx, namespaceNames); err != nil {
+		log.Error(err, "Error cleaning up cluster-scoped leftovers")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Teardown of Sandbox finished, releasing it")
+	controllerutil.RemoveFinalizer(sandbox, teardownFinalizer)
+	if err := r.Update(ctx, sandbox); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// teardownInProgress records the teardown progress in the status of the Sandbox and requeues it.
+func (r *SandboxReconciler) teardownInProgress(ctx context.Context, sandbox *devopsv1.Sandbox, teardown *devopsv1.SandboxTeardownStatus) (ctrl.Result, error) {
+	original := sandbox.Status.DeepCopy()
+
+	sandbox.Status.Teardown = teardown
+	pkgsandbox.UpdatePhase(sandbox)
+
+	if !equality.Semantic.DeepEqual(original, &sandbox.Status) {
+		if err := r.Status().Update(ctx, sandbox); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: teardownRequeueInterval}, nil
+}
+
+// teardownNamespaceNames returns the names of all namespaces of the Sandbox, including those that were provisioned
+// before, but are no longer part of its spec. The primary namespace comes first.
+func teardownNamespaceNames(sandbox *devopsv1.Sandbox) []string {
+	names := pkgsandbox.NamespaceNames(sandbox)
+	for _, status := range sandbox.Status.Namespaces {
+		if !containsString(names, status.Name) {
+			names = append(names, status.Name)
+		}
+	}
+
+	return names
+}
+
+// namespaceTeardownStatus reports which finalizers and conditions are keeping the namespaces from being removed.
+func namespaceTeardownStatus(namespaces []*corev1.Namespace) *devopsv1.SandboxTeardownStatus {
+	names := []string{}
+	finalizers := []string{}
+	messages := []string{}
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+
+		for _, f := range ns.Finalizers {
+			if !containsString(finalizers, f) {
+				finalizers = append(finalizers, f)
+			}
+		}
+		for _, f := range ns.Spec.Finalizers {
+			if !containsString(finalizers, string(f)) {
+				finalizers = append(finalizers, string(f))
+			}
+		}
+
+		for _, condition := range ns.Status.Conditions {
+			if condition.Status == corev1.ConditionTrue && condition.Message != "" {
+				messages = append(messages, condition.Message)
+			}
+		}
+	}
+
+	message := fmt.Sprintf("Waiting for namespace %s to be removed", strings.Join(names, ", "))
+	if len(messages) > 0 {
+		message = fmt.Sprintf("%s: %s", message, strings.Join(messages, "; "))
+	}
+
+	return &devopsv1.SandboxTeardownStatus{
+		Step:               devopsv1.TeardownDeletingNamespace,
+		BlockingFinalizers: finalizers,
+		Message:            message,
+	}
+}
+
+// runPreDeleteHooks starts a Job for every pre-delete hook of the Sandbox and returns the names of the hooks that
+// have not yet finished. Failed hooks and hooks that exceed their timeout are considered finished.
+func (r *SandboxReconciler) runPreDeleteHooks(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string) ([]string, error) {
+	log := r.Log.WithValues("sandbox", sandbox.Name)
+	now := clock.Ctx(ctx).Now()
+
+	pending := []string{}
+	for _, hook := range sandbox.Spec.PreDeleteHooks {
+		job := &batchv1.Job{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pkgsandbox.HookJobName(hook)}, job)
+		if errors.IsNotFound(err) {
+			job = newHookJob(sandbox, hook, namespace)
+			if err := ctrl.SetControllerReference(sandbox, job, r.Scheme); err != nil {
+				return nil, err
+			}
+
+			if err := r.Create(ctx, job); err != nil {
+				return nil, err
+			}
+
+			pending = append(pending, hook.Name)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		timeout := defaultHookTimeout
+		if hook.Timeout != nil {
+			timeout = hook.Timeout.Duration
+		}
+
+		switch {
+		case job.Status.Succeeded > 0:
+			continue
+		case isJobFailed(job):
+			log.Info("Pre-delete hook failed, continuing teardown", "hook", hook.Name)
+		case now.After(job.CreationTimestamp.Add(timeout)):
+			log.Info("Pre-delete hook timed out, continuing teardown", "hook", hook.Name)
+		default:
+			pending = append(pending, hook.Name)
+		}
+	}
+
+	return pending, nil
+}
+
+func newHookJob(sandbox *devopsv1.Sandbox, hook devopsv1.SandboxHook, namespace string) *batchv1.Job {
+	backoffLimit := int32(0)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pkgsandbox.HookJobName(hook),
+			Namespace: namespace,
+			Labels:    sandboxLabels(sandbox),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "hook",
+							Image:   hook.Image,
+							Command: hook.Command,
+							Args:    hook.Args,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cleanupLeftovers removes the cluster-scoped objects that outlive the sandbox namespaces, which are the released
+// PersistentVolumes that were claimed from within the namespaces. Volumes with the Retain reclaim policy are kept, as
+// their data is meant to outlive the claim.
+func (r *SandboxReconciler) cleanupLeftovers(ctx context.Context, namespaces []string) error {
+	volumes := &corev1.PersistentVolumeList{}
+	if err := r.List(ctx, volumes, &client.ListOptions{}); err != nil {
+		return err
+	}
+
+	for i := range volumes.Items {
+		pv := &volumes.Items[i]
+		if pv.Spec.ClaimRef == nil || !containsString(namespaces, pv.Spec.ClaimRef.Namespace) {
+			continue
+		}
+
+		if pv.Status.Phase != corev1.VolumeReleased && pv.Status.Phase != corev1.VolumeFailed {
+			continue
+		}
+
+		if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+			continue
+		}
+
+		if err := r.Delete(ctx, pv); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestTeardown(t *testing.T) {
+	ctx := context.Background()
+	now := metav1.Now()
+
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-1",
+			UID:               "1234",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{teardownFinalizer},
+		},
+		Spec: devopsv1.SandboxSpec{
+			User: "jdoe",
+			PreDeleteHooks: []devopsv1.SandboxHook{
+				{Name: "backup", Image: "busybox"},
+			},
+		},
+	}
+
+	r := newTestReconciler(t, sandbox)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "sandbox-jdoe-test-1"}}
+	assert.NilError(t, ctrl.SetControllerReference(sandbox, ns, r.Scheme))
+	assert.NilError(t, r.Create(ctx, ns))
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// The pre-delete hook is started and the namespace is kept until it finishes
+	result, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, teardownRequeueInterval)
+	assert.Equal(t, getSandbox(t, r, sandbox.Name).Status.Teardown.Step, devopsv1.TeardownPreDeleteHooks)
+
+	job := &batchv1.Job{}
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "sandbox-pre-delete-backup"}, job))
+	job.Status.Succeeded = 1
+	assert.NilError(t, r.Status().Update(ctx, job))
+
+	// Once the hook succeeded, the namespace is deleted
+	_, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Assert(t, errors.IsNotFound(r.Get(ctx, types.NamespacedName{Name: ns.Name}, &corev1.Namespace{})))
+
+	// Once the namespace is gone, the Sandbox is released
+	result, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.Equal(t, len(getSandbox(t, r, sandbox.Name).Finalizers), 0)
+}
+
+func TestCleanupLeftovers(t *testing.T) {
+	ctx := context.Background()
+
+	var tests = map[string]struct {
+		namespace     string
+		phase         corev1.PersistentVolumePhase
+		reclaimPolicy corev1.PersistentVolumeReclaimPolicy
+		deleted       bool
+	}{
+		"Released volume":                  {"sandbox-jdoe-test-1", corev1.VolumeReleased, corev1.PersistentVolumeReclaimDelete, true},
+		"Failed volume":                    {"sandbox-jdoe-test-1", corev1.VolumeFailed, corev1.PersistentVolumeReclaimDelete, true},
+		"Bound volume":                     {"sandbox-jdoe-test-1", corev1.VolumeBound, corev1.PersistentVolumeReclaimDelete, false},
+		"Released volume that is retained": {"sandbox-jdoe-test-1", corev1.VolumeReleased, corev1.PersistentVolumeReclaimRetain, false},
+		"Failed volume that is retained":   {"sandbox-jdoe-test-1", corev1.VolumeFailed, corev1.PersistentVolumeReclaimRetain, false},
+		"Volume of another namespace":      {"other", corev1.VolumeReleased, corev1.PersistentVolumeReclaimDelete, false},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					ClaimRef:                      &corev1.ObjectReference{Namespace: data.namespace, Name: "data"},
+					PersistentVolumeReclaimPolicy: data.reclaimPolicy,
+				},
+				Status: corev1.PersistentVolumeStatus{Phase: data.phase},
+			}
+			r := newTestReconciler(t, pv)
+
+			assert.NilError(t, r.cleanupLeftovers(ctx, []string{"sandbox-jdoe-test-1"}))
+
+			err := r.Get(ctx, types.NamespacedName{Name: pv.Name}, &corev1.PersistentVolume{})
+			if data.deleted {
+				assert.Assert(t, errors.IsNotFound(err), err)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups=devops.stackstate.com,resources=sandboxtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets;serviceaccounts;services;persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+
+// templateObjectsAnnotation records on a sandbox namespace which objects were applied from the SandboxTemplate, so
+// that the objects that are removed from the template are deleted.
+const templateObjectsAnnotation = "sandboxer/template-objects"
+
+// templateKinds are the kinds of objects that a SandboxTemplate can contain, the operator is granted access to exactly
+// these by the RBAC markers above.
+var templateKinds = map[schema.GroupKind]bool{
+	{Kind: "ConfigMap"}:                           true,
+	{Kind: "Secret"}:                              true,
+	{Kind: "ServiceAccount"}:                      true,
+	{Kind: "Service"}:                             true,
+	{Kind: "PersistentVolumeClaim"}:               true,
+	{Group: "apps", Kind: "Deployment"}:           true,
+	{Group: "apps", Kind: "StatefulSet"}:          true,
+	{Group: "batch", Kind: "Job"}:                 true,
+	{Group: "batch", Kind: "CronJob"}:             true,
+	{Group: "networking.k8s.io", Kind: "Ingress"}: true,
+}
+
+// findTemplate returns the SandboxTemplate referenced by the Sandbox, or nil if it does not reference one. A missing
+// SandboxTemplate is returned as a NotFound error.
+func (r *SandboxReconciler) findTemplate(ctx context.Context, sandbox *devopsv1.Sandbox) (*devopsv1.SandboxTemplate, error) {
+	if sandbox.Spec.TemplateRef == nil {
+		return nil, nil
+	}
+
+	template := &devopsv1.SandboxTemplate{}
+	if err := r.Get(ctx, types.NamespacedName{Name: sandbox.Spec.TemplateRef.Name}, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// withTemplateDefaults returns a copy of the Sandbox where the spec fields that are left empty are
+// taken from the defaults of the SandboxTemplate.
+func withTemplateDefaults(sandbox *devopsv1.Sandbox, template *devopsv1.SandboxTemplate) *devopsv1.Sandbox {
+	sb := sandbox.DeepCopy()
+	if template == nil {
+		return sb
+	}
+
+	defaults := template.Spec.Defaults.DeepCopy()
+	if sb.Spec.Groups == nil {
+		sb.Spec.Groups = defaults.Groups
+	}
+	if sb.Spec.Isolation == "" {
+		sb.Spec.Isolation = defaults.Isolation
+	}
+
+	if sb.Spec.Resources == nil {
+		sb.Spec.Resources = defaults.Resources
+	} else if defaults.Resources != nil {
+		resources := sb.Spec.Resources
+		if resources.CPU == nil {
+			resources.CPU = defaults.Resources.CPU
+		}
+		if resources.Memory == nil {
+			resources.Memory = defaults.Resources.Memory
+		}
+		if resources.Storage == nil {
+			resources.Storage = defaults.Resources.Storage
+		}
+		if resources.Pods == nil {
+			resources.Pods = defaults.Resources.Pods
+		}
+		if resources.Services == nil {
+			resources.Services = defaults.Resources.Services
+		}
+		if resources.PersistentVolumeClaims == nil {
+			resources.PersistentVolumeClaims = defaults.Resources.PersistentVolumeClaims
+		}
+		resources.DefaultRequest = withDefaults(resources.DefaultRequest, defaults.Resources.DefaultRequest)
+		resources.DefaultLimit = withDefaults(resources.DefaultLimit, defaults.Resources.DefaultLimit)
+	}
+
+	return sb
+}
+
+// reconcileTemplateObjects renders the objects of the SandboxTemplate and creates or updates them in the
+// sandbox namespace. Objects that were applied before, but are no longer part of the template, are deleted.
+func (r *SandboxReconciler) reconcileTemplateObjects(ctx context.Context, sandbox *devopsv1.Sandbox, template *devopsv1.SandboxTemplate, ns *corev1.Namespace) error {
+	objects := []*unstructured.Unstructured{}
+	if template != nil {
+		data := map[string]interface{}{
+			"Sandbox":   sandbox,
+			"Namespace": ns.Name,
+		}
+
+		// All objects are checked before any is applied, so that an invalid template does not get applied partially
+		for i, raw := range template.Spec.Objects {
+			desired, err := renderObject(raw, data)
+			if err != nil {
+				return fmt.Errorf("unable to render object %d of template %s: %w", i, template.Name, err)
+			}
+
+			if !templateKinds[desired.GroupVersionKind().GroupKind()] {
+				return fmt.Errorf("%s %s of template %s is not supported, the kinds that a template can contain are %s",
+					desired.GetKind(), desired.GetName(), template.Name, strings.Join(supportedTemplateKinds(), ", "))
+			}
+
+			objects = append(objects, desired)
+		}
+	}
+
+	applied := []string{}
+	for _, desired := range objects {
+		if err := r.applyTemplateObject(ctx, sandbox, desired, ns.Name); err != nil {
+			return fmt.Errorf("unable to apply %s %s of template %s: %w", desired.GetKind(), desired.GetName(), template.Name, err)
+		}
+
+		applied = append(applied, templateObjectKey(desired.GroupVersionKind().GroupKind(), desired.GetName()))
+	}
+
+	return r.pruneTemplateObjects(ctx, sandbox, ns, applied)
+}
+
+// pruneTemplateObjects deletes the objects that were applied to the namespace before, but are not in applied, and
+// records the applied objects in the namespace.
+func (r *SandboxReconciler) pruneTemplateObjects(ctx context.Context, sandbox *devopsv1.Sandbox, ns *corev1.Namespace, applied []string) error {
+	previous := ns.Annotations[templateObjectsAnnotation]
+	for _, key := range strings.Split(previous, ",") {
+		if key == "" || containsString(applied, key) {
+			continue
+		}
+
+		if err := r.deleteTemplateObject(ctx, sandbox, ns.Name, key); err != nil {
+			return fmt.Errorf("unable to delete %s that was removed from the template: %w", key, err)
+		}
+	}
+
+	current := strings.Join(applied, ",")
+	if current == previous {
+		return nil
+	}
+
+	patch := client.MergeFrom(ns.DeepCopy())
+	if current == "" {
+		delete(ns.Annotations, templateObjectsAnnotation)
+	} else {
+		ns.Annotations = mergeMaps(ns.Annotations, map[string]string{templateObjectsAnnotation: current})
+	}
+
+	return r.Patch(ctx, ns, patch)
+}
+
+// deleteTemplateObject deletes the object with the given key from the namespace, if it is still controlled by the
+// Sandbox.
+func (r *SandboxReconciler) deleteTemplateObject(ctx context.Context, sandbox *devopsv1.Sandbox, namespace string, key string) error {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return nil
+	}
+
+	mapping, err := r.RESTMapper().RESTMapping(schema.ParseGroupKind(key[:i]))
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil // The kind is no longer served, so there is nothing to delete
+		}
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: key[i+1:]}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !metav1.IsControlledBy(obj, sandbox) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// templateObjectKey identifies an object of a template within a namespace, e.g. Deployment.apps/web
+func templateObjectKey(groupKind schema.GroupKind, name string) string {
+	return groupKind.String() + "/" + name
+}
+
+// supportedTemplateKinds returns the kinds that a SandboxTemplate can contain, sorted by name.
+func supportedTemplateKinds() []string {
+	kinds := []string{}
+	for groupKind := range templateKinds {
+		kinds = append(kinds, groupKind.String())
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// applyTemplateObject creates or updates a single rendered template object in the sandbox namespace. Only the fields
+// that the template sets are updated, the fields that the API server fills in, like the volume of a bound
+// PersistentVolumeClaim or the selector of a Job, are kept. These are often immutable, so replacing them would make
+// every update fail.
+func (r *SandboxReconciler) applyTemplateObject(ctx context.Context, sandbox *devopsv1.Sandbox, desired *unstructured.Unstructured, namespace string) error {
+	mapping, err := r.RESTMapper().RESTMapping(desired.GroupVersionKind().GroupKind(), desired.GroupVersionKind().Version)
+	if err != nil {
+		return err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return fmt.Errorf("only namespaced objects are supported")
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(desired.GroupVersionKind())
+	obj.SetName(desired.GetName())
+	obj.SetNamespace(namespace)
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		for key, value := range desired.Object {
+			if key == "apiVersion" || key == "kind" || key == "metadata" || key == "status" {
+				continue
+			}
+			obj.Object[key] = mergeValue(obj.Object[key], value)
+		}
+
+		obj.SetLabels(mergeMaps(obj.GetLabels(), desired.GetLabels(), sandboxLabels(sandbox)))
+		// An empty map would be dropped by the API server, and be updated again on every reconcile
+		if annotations := mergeMaps(obj.GetAnnotations(), desired.GetAnnotations()); len(annotations) > 0 {
+			obj.SetAnnotations(annotations)
+		}
+		if err := keepHibernated(obj); err != nil {
+			return err
+		}
+
+		return ctrl.SetControllerReference(sandbox, obj, r.Scheme)
+	})
+
+	return err
+}
+
+// mergeValue merges the desired value of a field into the current value. Maps are merged key by key, and lists of the
+// same length item by item, so that the fields that are not in the desired value are kept. Any other desired value
+// replaces the current one.
+func mergeValue(current interface{}, desired interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return runtime.DeepCopyJSONValue(d)
+		}
+
+		merged := make(map[string]interface{}, len(c))
+		for key, value := range c {
+			merged[key] = value
+		}
+		for key, value := range d {
+			merged[key] = mergeValue(c[key], value)
+		}
+		return merged
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			return runtime.DeepCopyJSONValue(d)
+		}
+
+		merged := make([]interface{}, len(d))
+		for i := range d {
+			merged[i] = mergeValue(c[i], d[i])
+		}
+		return merged
+	default:
+		return runtime.DeepCopyJSONValue(d)
+	}
+}
+
+// renderObject decodes the raw manifest and renders every string value in it as a Go template.
+func renderObject(raw runtime.RawExtension, data map[string]interface{}) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+		return nil, err
+	}
+
+	rendered, err := renderValue(obj.Object, data)
+	if err != nil {
+		return nil, err
+	}
+
+	obj.Object = rendered.(map[string]interface{})
+	return obj, nil
+}
+
+func renderValue(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rendered
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = rendered
+		}
+		return v, nil
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+
+		t, err := template.New("object").Parse(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	default:
+		return v, nil
+	}
+}
+
+// sandboxesForTemplate maps a SandboxTemplate to reconcile requests for all Sandboxes that use it.
+func (r *SandboxReconciler) sandboxesForTemplate(obj client.Object) []reconcile.Request {
+	sandboxes := &devopsv1.SandboxList{}
+	if err := r.List(context.Background(), sandboxes); err != nil {
+		r.Log.Error(err, "Unable to list sandboxes for template", "template", obj.GetName())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, sb := range sandboxes.Items {
+		if sb.Spec.TemplateRef != nil && sb.Spec.TemplateRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: sb.Name}})
+		}
+	}
+
+	return requests
+}
+
+// containsAll checks whether all entries of subset are present in m.
+func containsAll(m map[string]string, subset map[string]string) bool {
+	for key, value := range subset {
+		if v, ok := m[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeMaps merges the given maps into a new map, later maps taking precedence.
+func mergeMaps(maps ...map[string]string) map[string]string {
+	result := map[string]string{}
+	for _, m := range maps {
+		for key, value := range m {
+			result[key] = value
+		}
+	}
+
+	return result
+}
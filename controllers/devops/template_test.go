@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRenderObject(t *testing.T) {
+	sb := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+
+	raw := runtime.RawExtension{
+		Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"info"},"data":{"owner":"{{ .Sandbox.Spec.User }}","ns":"{{ .Namespace }}","plain":"value"}}`),
+	}
+
+	obj, err := renderObject(raw, map[string]interface{}{"Sandbox": sb, "Namespace": "sandbox-jdoe-test-1"})
+	assert.NilError(t, err)
+	assert.Equal(t, obj.GetKind(), "ConfigMap")
+	assert.Equal(t, obj.GetName(), "info")
+
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	assert.NilError(t, err)
+	assert.Equal(t, data["owner"], "jdoe")
+	assert.Equal(t, data["ns"], "sandbox-jdoe-test-1")
+	assert.Equal(t, data["plain"], "value")
+}
+
+func TestWithTemplateDefaults(t *testing.T) {
+	templateCPU := resource.MustParse("2")
+	templateMemory := resource.MustParse("4Gi")
+	sandboxCPU := resource.MustParse("1")
+
+	template := &devopsv1.SandboxTemplate{
+		Spec: devopsv1.SandboxTemplateSpec{
+			Defaults: devopsv1.SandboxDefaults{
+				Groups:    []string{"developers"},
+				Isolation: devopsv1.IsolationIsolated,
+				Resources: &devopsv1.SandboxResources{
+					CPU:    &templateCPU,
+					Memory: &templateMemory,
+				},
+			},
+		},
+	}
+
+	sb := &devopsv1.Sandbox{
+		Spec: devopsv1.SandboxSpec{
+			Isolation: devopsv1.IsolationOpen,
+			Resources: &devopsv1.SandboxResources{CPU: &sandboxCPU},
+		},
+	}
+
+	result := withTemplateDefaults(sb, template)
+	assert.DeepEqual(t, result.Spec.Groups, []string{"developers"})
+	assert.Equal(t, result.Spec.Isolation, devopsv1.IsolationOpen)
+	assert.Equal(t, result.Spec.Resources.CPU.String(), "1")
+	assert.Equal(t, result.Spec.Resources.Memory.String(), "4Gi")
+	assert.Assert(t, sb.Spec.Resources.Memory == nil, "original sandbox must not be modified")
+}
+
+// mappedClient adds a RESTMapper to the fake client, which does not have one.
+type mappedClient struct {
+	client.Client
+	mapper meta.RESTMapper
+}
+
+func (c *mappedClient) RESTMapper() meta.RESTMapper {
+	return c.mapper
+}
+
+func newTemplateReconciler(t *testing.T, objs ...client.Object) *SandboxReconciler {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion, batchv1.SchemeGroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), meta.RESTScopeNamespace)
+	mapper.Add(batchv1.SchemeGroupVersion.WithKind("Job"), meta.RESTScopeNamespace)
+
+	r := newTestReconciler(t, objs...)
+	r.Client = &mappedClient{Client: r.Client, mapper: mapper}
+	return r
+}
+
+func configMapManifest(name string) runtime.RawExtension {
+	return runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + name + `"},"data":{"owner":"{{ .Sandbox.Spec.User }}"}}`)}
+}
+
+func TestReconcileTemplateObjects(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	template := &devopsv1.SandboxTemplate{
+		ObjectMeta: v1.ObjectMeta{Name: "default"},
+		Spec: devopsv1.SandboxTemplateSpec{
+			Objects: []runtime.RawExtension{configMapManifest("info"), configMapManifest("settings")},
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "sandbox-jdoe-test-1"}}
+
+	r := newTemplateReconciler(t, sandbox, ns)
+	assert.NilError(t, r.reconcileTemplateObjects(ctx, sandbox, template, ns))
+
+	for _, name := range []string{"info", "settings"} {
+		cm := &corev1.ConfigMap{}
+		assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: name}, cm))
+		assert.Equal(t, cm.Data["owner"], "jdoe")
+	}
+	assert.Equal(t, ns.Annotations[templateObjectsAnnotation], "ConfigMap/info,ConfigMap/settings")
+
+	// An object that is removed from the template is deleted
+	template.Spec.Objects = template.Spec.Objects[:1]
+	assert.NilError(t, r.reconcileTemplateObjects(ctx, sandbox, template, ns))
+
+	err := r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "settings"}, &corev1.ConfigMap{})
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "info"}, &corev1.ConfigMap{}))
+
+	// Objects that are not controlled by the Sandbox are kept, and the record is removed with the template
+	other := &corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Namespace: ns.Name, Name: "info"}}
+	assert.NilError(t, r.Delete(ctx, other))
+	assert.NilError(t, r.Create(ctx, other))
+	assert.NilError(t, r.reconcileTemplateObjects(ctx, sandbox, nil, ns))
+
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "info"}, &corev1.ConfigMap{}))
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: ns.Name}, ns))
+	_, ok := ns.Annotations[templateObjectsAnnotation]
+	assert.Assert(t, !ok)
+}
+
+// immutableClient rejects the updates of immutable fields, like the API server does, and counts the updates.
+type immutableClient struct {
+	client.Client
+	updates int
+}
+
+func (c *immutableClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates++
+
+	immutable := map[string][][]string{
+		"PersistentVolumeClaim": {{"spec", "volumeName"}, {"spec", "storageClassName"}},
+		"Job":                   {{"spec", "selector"}, {"spec", "template"}},
+	}
+
+	u := obj.(*unstructured.Unstructured)
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(u.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKeyFromObject(u), current); err != nil {
+		return err
+	}
+
+	for _, path := range immutable[u.GetKind()] {
+		before, _, _ := unstructured.NestedFieldNoCopy(current.Object, path...)
+		after, _, _ := unstructured.NestedFieldNoCopy(u.Object, path...)
+		if !equality.Semantic.DeepEqual(before, after) {
+			return errors.NewInvalid(u.GroupVersionKind().GroupKind(), u.GetName(), field.ErrorList{
+				field.Invalid(field.NewPath(path[0], path[1:]...), after, "field is immutable"),
+			})
+		}
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestReconcileTemplateObjectsKeepsServerFields(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "sandbox-jdoe-test-1"}}
+	template := &devopsv1.SandboxTemplate{
+		ObjectMeta: v1.ObjectMeta{Name: "default"},
+		Spec: devopsv1.SandboxTemplateSpec{
+			Objects: []runtime.RawExtension{
+				{Raw: []byte(`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"name":"data"},
+					"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"1Gi"}}}}`)},
+				{Raw: []byte(`{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"init"},
+					"spec":{"template":{"metadata":{"labels":{"app":"init"}},"spec":{"restartPolicy":"Never",
+					"containers":[{"name":"init","image":"busybox"}]}}}}`)},
+			},
+		},
+	}
+
+	// The objects as they were created from the template before, with the fields that the API server filled in
+	storageClass := "standard"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Namespace: ns.Name, Name: "data", Labels: sandboxLabels(sandbox)},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+			VolumeName:       "pvc-5678",
+			StorageClassName: &storageClass,
+		},
+	}
+	jobLabels := map[string]string{"app": "init", "controller-uid": "9012", "job-name": "init"}
+	job := &batchv1.Job{
+		ObjectMeta: v1.ObjectMeta{Namespace: ns.Name, Name: "init", Labels: sandboxLabels(sandbox)},
+		Spec: batchv1.JobSpec{
+			Selector: &v1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "9012"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: jobLabels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     "init",
+						Image:                    "busybox",
+						ImagePullPolicy:          corev1.PullAlways,
+						TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+						TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+					}},
+				},
+			},
+		},
+	}
+
+	r := newTemplateReconciler(t, sandbox, ns)
+	for _, obj := range []client.Object{pvc, job} {
+		assert.NilError(t, ctrl.SetControllerReference(sandbox, obj, r.Scheme))
+		assert.NilError(t, r.Create(ctx, obj))
+	}
+	c := &immutableClient{Client: r.Client}
+	r.Client = c
+
+	// Nothing is updated when the template did not change
+	assert.NilError(t, r.reconcileTemplateObjects(ctx, sandbox, template, ns))
+	assert.Equal(t, c.updates, 0)
+
+	// A change of the template keeps the fields that the API server filled in
+	template.Spec.Objects[0].Raw = bytes.Replace(template.Spec.Objects[0].Raw, []byte("1Gi"), []byte("2Gi"), 1)
+	assert.NilError(t, r.reconcileTemplateObjects(ctx, sandbox, template, ns))
+	assert.Equal(t, c.updates, 1)
+
+	assert.NilError(t, r.Get(ctx, client.ObjectKeyFromObject(pvc), pvc))
+	assert.Equal(t, pvc.Spec.Resources.Requests.Storage().String(), "2Gi")
+	assert.Equal(t, pvc.Spec.VolumeName, "pvc-5678")
+	assert.Equal(t, *pvc.Spec.StorageClassName, "standard")
+
+	assert.NilError(t, r.Get(ctx, client.ObjectKeyFromObject(job), job))
+	assert.DeepEqual(t, job.Spec.Template.Labels, jobLabels)
+	assert.Equal(t, job.Spec.Template.Spec.Containers[0].TerminationMessagePath, corev1.TerminationMessagePathDefault)
+}
+
+func TestReconcileTemplateObjectsUnsupportedKind(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	template := &devopsv1.SandboxTemplate{
+		ObjectMeta: v1.ObjectMeta{Name: "default"},
+		Spec: devopsv1.SandboxTemplateSpec{
+			Objects: []runtime.RawExtension{
+				configMapManifest("info"),
+				{Raw: []byte(`{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"Role","metadata":{"name":"escalate"}}`)},
+			},
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "sandbox-jdoe-test-1"}}
+
+	r := newTemplateReconciler(t, sandbox, ns)
+	err := r.reconcileTemplateObjects(ctx, sandbox, template, ns)
+	assert.ErrorContains(t, err, "Role escalate of template default is not supported")
+
+	// Nothing of an invalid template is applied
+	err = r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: "info"}, &corev1.ConfigMap{})
+	assert.Assert(t, errors.IsNotFound(err))
+}
+
+func TestReconcileMissingTemplate(t *testing.T) {
+	ctx := context.Background()
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "test-1", UID: "1234"},
+		Spec: devopsv1.SandboxSpec{
+			User:        "jdoe",
+			TemplateRef: &devopsv1.SandboxTemplateReference{Name: "default"},
+		},
+	}
+
+	r := newTemplateReconciler(t, sandbox)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// The Sandbox is provisioned without the template, and requeued with backoff until it exists
+	result, err := r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{Requeue: true})
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: "sandbox-jdoe-test-1"}, &corev1.Namespace{}))
+
+	condition := meta.FindStatusCondition(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionTemplateReady)
+	assert.Assert(t, condition != nil)
+	assert.Equal(t, condition.Status, v1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "TemplateNotFound")
+
+	// Once the template is created, its objects are applied
+	assert.NilError(t, r.Create(ctx, &devopsv1.SandboxTemplate{
+		ObjectMeta: v1.ObjectMeta{Name: "default"},
+		Spec:       devopsv1.SandboxTemplateSpec{Objects: []runtime.RawExtension{configMapManifest("info")}},
+	}))
+
+	result, err = r.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.NilError(t, r.Get(ctx, types.NamespacedName{Namespace: "sandbox-jdoe-test-1", Name: "info"}, &corev1.ConfigMap{}))
+	assert.Assert(t, meta.IsStatusConditionTrue(getSandbox(t, r, sandbox.Name).Status.Conditions, devopsv1.ConditionTemplateReady))
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var podMetricsListKind = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// +kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims,verbs=get;list
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+// SandboxUsageReconciler periodically aggregates the resources that are requested and used in the namespaces of a
+// Sandbox, and records them with their cost in the status of the Sandbox.
+type SandboxUsageReconciler struct {
+	client.Client
+	// Reader reads the pods, persistent volume claims and metrics directly from the API server, so that they do not
+	// need to be cached for the whole cluster.
+	Reader client.Reader
+	Log    logr.Logger
+	Config *Config
+}
+
+func (r *SandboxUsageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("sandbox", req.NamespacedName)
+
+	sandbox := &devopsv1.Sandbox{}
+	if err := r.Get(ctx, req.NamespacedName, sandbox); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !sandbox.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	usage, err := r.aggregateUsage(ctx, pkgsandbox.NamespaceNames(sandbox))
+	if err != nil {
+		log.Error(err, "Failed to aggregate resource usage")
+		return ctrl.Result{}, err
+	}
+
+	usage.HourlyCost = strconv.FormatFloat(hourlyCost(usage, r.Config.Prices), 'f', 4, 64)
+	usage.Currency = r.Config.Currency
+
+	now := metav1.NewTime(clock.Ctx(ctx).Now())
+	usage.ObservedAt = &now
+
+	patch := client.MergeFrom(sandbox.DeepCopy())
+	sandbox.Status.Usage = usage
+	if err := r.Status().Patch(ctx, sandbox, patch); err != nil {
+		log.Error(err, "Failed to update resource usage")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: r.Config.UsageInterval}, nil
+}
+
+// aggregateUsage sums the resource requests of the running pods and the persistent volume claims in the namespaces,
+// and the resource usage of the pods if the metrics API is available.
+func (r *SandboxUsageReconciler) aggregateUsage(ctx context.Context, namespaces []string) (*devopsv1.SandboxUsage, error) {
+	requests := corev1.ResourceList{}
+	var used corev1.ResourceList
+
+	for _, namespace := range namespaces {
+		pods := &corev1.PodList{}
+		if err := r.Reader.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			addResources(requests, podRequests(pod))
+		}
+
+		claims := &corev1.PersistentVolumeClaimList{}
+		if err := r.Reader.List(ctx, claims, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+
+		for _, claim := range claims.Items {
+			if storage, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+				addResources(requests, corev1.ResourceList{corev1.ResourceStorage: storage})
+			}
+		}
+
+		podUsage, err := r.podUsage(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if podUsage != nil {
+			if used == nil {
+				used = corev1.ResourceList{}
+			}
+			addResources(used, podUsage)
+		}
+	}
+
+	return &devopsv1.SandboxUsage{Requests: requests, Used: used}, nil
+}
+
+// podUsage returns the CPU and memory usage of the pods in the namespace as reported by the metrics API, or nil if
+// the metrics API is not available in the cluster.
+func (r *SandboxUsageReconciler) podUsage(ctx context.Context, namespace string) (corev1.ResourceList, error) {
+	metrics := &unstructured.UnstructuredList{}
+	metrics.SetGroupVersionKind(podMetricsListKind)
+	if err := r.Reader.List(ctx, metrics, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) || errors.IsNotFound(err) || errors.IsServiceUnavailable(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	usage := corev1.ResourceList{}
+	for _, item := range metrics.Items {
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			values, _, err := unstructured.NestedStringMap(container, "usage")
+			if err != nil {
+				return nil, err
+			}
+
+			for name, value := range values {
+				quantity, err := resource.ParseQuantity(value)
+				if err != nil {
+					return nil, err
+				}
+				addResources(usage, corev1.ResourceList{corev1.ResourceName(name): quantity})
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// podRequests returns the effective resource requests of a pod, which is the larger of the sum of its containers and
+// the largest of its init containers, plus the pod overhead.
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	addResources(requests, pod.Spec.Overhead)
+	return requests
+}
+
+// addResources adds the quantities of the resources in add to list.
+func addResources(list corev1.ResourceList, add corev1.ResourceList) {
+	for name, quantity := range add {
+		current := list[name]
+		current.Add(quantity)
+		list[name] = current
+	}
+}
+
+// hourlyCost prices the larger of the requested and used amount of every resource, so that a sandbox pays for what
+// it reserves as well as for what it uses beyond its reservation.
+func hourlyCost(usage *devopsv1.SandboxUsage, prices PriceTable) float64 {
+	const gibibyte = 1024 * 1024 * 1024
+
+	cost := 0.0
+	for name, price := range prices {
+		amount := usage.Requests[name]
+		if used, ok := usage.Used[name]; ok && used.Cmp(amount) > 0 {
+			amount = used
+		}
+
+		if name == corev1.ResourceCPU {
+			cost += float64(amount.MilliValue()) / 1000 * price
+		} else {
+			cost += float64(amount.Value()) / gibibyte * price
+		}
+	}
+
+	return cost
+}
+
+func (r *SandboxUsageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Status updates do not change the generation, so that patching the usage does not trigger another aggregation
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sandbox-usage").
+		For(&devopsv1.Sandbox{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
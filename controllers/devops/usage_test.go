@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/zapr"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"go.uber.org/zap"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileUsage(t *testing.T) {
+	ctx := context.Background()
+	namespace := "sandbox-jdoe-test-1"
+
+	sandbox := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+	}
+	web := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "web"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Resources: requests("2", "512Mi")}},
+			Containers: []corev1.Container{
+				{Name: "web", Resources: requests("500m", "1Gi")},
+				{Name: "proxy", Resources: requests("500m", "1Gi")},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	completed := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "job"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "job", Resources: requests("4", "8Gi")}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "data"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}},
+		},
+	}
+	metrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": "web"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "web", "usage": map[string]interface{}{"cpu": "100m", "memory": "3Gi"}},
+		},
+	}}
+
+	var tests = map[string]struct {
+		objs         []client.Object
+		expectedUsed corev1.ResourceList
+		expectedCost string
+	}{
+		// 2 cores * 0.03 + 2 GiB * 0.004 + 10 GiB * 0.0001
+		"Requests only": {[]client.Object{web, completed, claim}, corev1.ResourceList{}, "0.0690"},
+		// Memory is priced by its usage of 3 GiB, as it exceeds the requests
+		"With metrics": {[]client.Object{web, completed, claim, metrics}, corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("3Gi"),
+		}, "0.0730"},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestUsageReconciler(t, append([]client.Object{sandbox.DeepCopy()}, data.objs...)...)
+
+			result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}})
+			assert.NilError(t, err)
+			assert.Equal(t, result.RequeueAfter, r.Config.UsageInterval)
+
+			updated := &devopsv1.Sandbox{}
+			assert.NilError(t, r.Get(ctx, types.NamespacedName{Name: sandbox.Name}, updated))
+			usage := updated.Status.Usage
+			assert.Assert(t, usage != nil)
+			assert.Equal(t, usage.Requests.Cpu().String(), "2")
+			assert.Equal(t, usage.Requests.Memory().String(), "2Gi")
+			assert.Equal(t, usage.Requests.Storage().String(), "10Gi")
+			assert.Equal(t, len(usage.Used), len(data.expectedUsed))
+			for name, quantity := range data.expectedUsed {
+				used := usage.Used[name]
+				assert.Equal(t, used.Cmp(quantity), 0, name)
+			}
+			assert.Equal(t, usage.HourlyCost, data.expectedCost)
+			assert.Equal(t, usage.Currency, "USD")
+		})
+	}
+}
+
+func newTestUsageReconciler(t *testing.T, objs ...client.Object) *SandboxUsageReconciler {
+	// The metrics API is served as unstructured objects, which the fake client only lists when their kinds are known
+	scheme := newTestReconciler(t).Scheme
+	scheme.AddKnownTypeWithName(podMetricsListKind.GroupVersion().WithKind("PodMetrics"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(podMetricsListKind, &unstructured.UnstructuredList{})
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &SandboxUsageReconciler{
+		Client: c,
+		Reader: c,
+		Log:    zapr.NewLogger(zap.NewNop()),
+		Config: &Config{
+			UsageInterval: 15 * time.Minute,
+			Prices:        PriceTable{corev1.ResourceCPU: 0.03, corev1.ResourceMemory: 0.004, corev1.ResourceStorage: 0.0001},
+			Currency:      "USD",
+		},
+	}
+}
+
+func requests(cpu, memory string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}}
+}
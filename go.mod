@@ -8,6 +8,7 @@ require (
 	github.com/butonic/zerologr v0.0.0-20191210074216-d798ee237d84
 	github.com/go-logr/logr v0.4.0
 	github.com/go-logr/zapr v0.4.0
+	github.com/google/go-cmp v0.5.4
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/rs/zerolog v1.20.0
@@ -21,4 +22,5 @@ require (
 	k8s.io/apimachinery v0.20.2
 	k8s.io/client-go v0.20.2
 	sigs.k8s.io/controller-runtime v0.8.1
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -2,6 +2,8 @@ package notification
 
 type MockNotifier struct {
 	Notifications []Notification
+	// Err decides whether Notify fails for a message, failed notifications are not recorded. It is optional.
+	Err func(message string) error
 }
 
 type Notification struct {
@@ -18,6 +20,12 @@ func NewMock() *MockNotifier {
 }
 
 func (m *MockNotifier) Notify(channel string, message string) error {
+	if m.Err != nil {
+		if err := m.Err(message); err != nil {
+			return err
+		}
+	}
+
 	m.Notifications = append(m.Notifications, Notification{channel, message})
 	return nil
 }
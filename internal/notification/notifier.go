@@ -3,3 +3,10 @@ package notification
 type Notifier interface {
 	Notify(channel string, message string) error
 }
+
+// ExtendNotifier is a Notifier that lets the owner of a Sandbox extend it from the notification.
+type ExtendNotifier interface {
+	Notifier
+	// NotifyWithExtend posts the message with buttons to extend the named Sandbox
+	NotifyWithExtend(channel string, message string, sandbox string) error
+}
@@ -0,0 +1,174 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// extendBlockID identifies the block with the extend buttons in a notification
+	extendBlockID = "sandbox-extend"
+	// maxInteractionSize limits the size of the payloads that are read, those of Slack are much smaller
+	maxInteractionSize = 1 << 20
+	// interactionTimeout limits the time to handle a click and reply to it, after it is acknowledged
+	interactionTimeout = time.Minute
+)
+
+// extendAction is a button in a notification, an extension of zero keeps the sandbox until it is deleted manually
+type extendAction struct {
+	id     string
+	text   string
+	extend time.Duration
+}
+
+var extendActions = []extendAction{
+	{"extend-day", "Extend 1 day", 24 * time.Hour},
+	{"extend-week", "Extend 1 week", 7 * 24 * time.Hour},
+	{"keep-manually", "Keep manually", 0},
+}
+
+// SandboxExtender changes the expiry of a Sandbox for the Slack user that clicked a button. It returns the reply to
+// the user, which explains why the Sandbox was not changed if the user is not allowed to.
+type SandboxExtender interface {
+	ExtendSandbox(ctx context.Context, name string, slackId string, by time.Duration) (string, error)
+	KeepSandbox(ctx context.Context, name string, slackId string) (string, error)
+}
+
+// InteractionHandler handles the interactive payloads that Slack sends when a button in a notification is clicked.
+type InteractionHandler struct {
+	Extender SandboxExtender
+	// SigningSecret verifies that the payloads are sent by Slack, all payloads are rejected without it
+	SigningSecret string
+	// pending tracks the clicks that are acknowledged, but not yet handled
+	pending sync.WaitGroup
+}
+
+// InteractionHandler returns the handler for the buttons in the notifications of the Slacker
+func (s *Slacker) InteractionHandler(extender SandboxExtender) *InteractionHandler {
+	return &InteractionHandler{Extender: extender, SigningSecret: s.config.SigningSecret}
+}
+
+// VerifiesInteractions returns whether a signing secret is configured, without it all interactions are rejected
+func (s *Slacker) VerifiesInteractions() bool {
+	return s.config.SigningSecret != ""
+}
+
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context())
+
+	if h.SigningSecret == "" {
+		logger.Error().Msg("Rejecting Slack interaction, as no signing secret is configured")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxInteractionSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, h.SigningSecret)
+	if err == nil {
+		_, err = verifier.Write(body)
+	}
+	if err == nil {
+		err = verifier.Ensure()
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("Rejecting Slack interaction with an invalid signature")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	callback := &slack.InteractionCallback{}
+	if err := json.Unmarshal([]byte(form.Get("payload")), callback); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects a response within 3 seconds, so the click is acknowledged first and handled afterwards. The
+	// outcome is replied through the response URL. The handling outlives the request, so it gets its own context.
+	w.WriteHeader(http.StatusOK)
+
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+
+		ctx, cancel := context.WithTimeout(logger.WithContext(context.Background()), interactionTimeout)
+		defer cancel()
+		h.handleActions(ctx, callback)
+	}()
+}
+
+// Shutdown waits until the acknowledged clicks are handled and replied to, or until the context is done. It is called
+// after the server stopped accepting requests, so that no extension is dropped when the operator stops.
+func (h *InteractionHandler) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handleActions handles the clicked extend buttons and replies the outcome to the user
+func (h *InteractionHandler) handleActions(ctx context.Context, callback *slack.InteractionCallback) {
+	logger := log.Ctx(ctx)
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.BlockID != extendBlockID {
+			continue
+		}
+
+		reply, err := h.handle(ctx, action, callback.User.ID)
+		if err != nil {
+			logger.Error().Err(err).Str("sandbox", action.Value).Msg("Failed to extend sandbox from Slack")
+			reply = "Sorry, the sandbox could not be changed: " + err.Error()
+		}
+
+		if err := slack.PostWebhookContext(ctx, callback.ResponseURL, &slack.WebhookMessage{Text: reply}); err != nil {
+			logger.Error().Err(err).Msg("Failed to reply to Slack interaction")
+		}
+	}
+}
+
+func (h *InteractionHandler) handle(ctx context.Context, action *slack.BlockAction, slackId string) (string, error) {
+	for _, a := range extendActions {
+		if a.id != action.ActionID {
+			continue
+		}
+
+		if a.extend == 0 {
+			return h.Extender.KeepSandbox(ctx, action.Value, slackId)
+		}
+
+		return h.Extender.ExtendSandbox(ctx, action.Value, slackId, a.extend)
+	}
+
+	return "Unknown action " + action.ActionID, nil
+}
@@ -0,0 +1,236 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"gotest.tools/v3/assert"
+)
+
+const signingSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+type fakeExtender struct {
+	calls []string
+}
+
+func (f *fakeExtender) ExtendSandbox(ctx context.Context, name string, slackId string, by time.Duration) (string, error) {
+	f.calls = append(f.calls, fmt.Sprintf("extend %s by %s for %s", name, by, slackId))
+	return "Sandbox " + name + " is extended", nil
+}
+
+func (f *fakeExtender) KeepSandbox(ctx context.Context, name string, slackId string) (string, error) {
+	f.calls = append(f.calls, fmt.Sprintf("keep %s for %s", name, slackId))
+	return "Sandbox " + name + " is kept", nil
+}
+
+func TestInteractionHandler(t *testing.T) {
+	// The fake Slack server receives the replies on the response URL
+	replies := []string{}
+	fakeSlack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &slack.WebhookMessage{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(msg))
+		replies = append(replies, msg.Text)
+	}))
+	defer fakeSlack.Close()
+
+	var tests = map[string]struct {
+		actionId  string
+		secret    string
+		status    int
+		expected  []string
+		replyText string
+	}{
+		"Extend by a day":   {"extend-day", signingSecret, http.StatusOK, []string{"extend test-1 by 24h0m0s for U0123ABCD"}, "Sandbox test-1 is extended"},
+		"Extend by a week":  {"extend-week", signingSecret, http.StatusOK, []string{"extend test-1 by 168h0m0s for U0123ABCD"}, "Sandbox test-1 is extended"},
+		"Keep manually":     {"keep-manually", signingSecret, http.StatusOK, []string{"keep test-1 for U0123ABCD"}, "Sandbox test-1 is kept"},
+		"Invalid signature": {"extend-day", "not-the-secret", http.StatusUnauthorized, nil, ""},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			replies = []string{}
+			extender := &fakeExtender{}
+			handler := &InteractionHandler{Extender: extender, SigningSecret: signingSecret}
+
+			payload, err := json.Marshal(map[string]interface{}{
+				"type":         "block_actions",
+				"user":         map[string]string{"id": "U0123ABCD"},
+				"response_url": fakeSlack.URL,
+				"actions": []map[string]string{
+					{"action_id": data.actionId, "block_id": extendBlockID, "value": "test-1", "type": "button"},
+				},
+			})
+			assert.NilError(t, err)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, signedRequest(t, data.secret, url.Values{"payload": {string(payload)}}.Encode()))
+			handler.pending.Wait()
+
+			assert.Equal(t, w.Code, data.status)
+			assert.DeepEqual(t, extender.calls, data.expected)
+			if data.replyText != "" {
+				assert.DeepEqual(t, replies, []string{data.replyText})
+			} else {
+				assert.Equal(t, len(replies), 0)
+			}
+		})
+	}
+}
+
+// blockingExtender does not extend the sandbox until it is released
+type blockingExtender struct {
+	fakeExtender
+	release chan struct{}
+}
+
+func (b *blockingExtender) ExtendSandbox(ctx context.Context, name string, slackId string, by time.Duration) (string, error) {
+	<-b.release
+	return b.fakeExtender.ExtendSandbox(ctx, name, slackId, by)
+}
+
+func TestInteractionHandlerAcknowledgesFirst(t *testing.T) {
+	fakeSlack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fakeSlack.Close()
+
+	extender := &blockingExtender{release: make(chan struct{})}
+	handler := &InteractionHandler{Extender: extender, SigningSecret: signingSecret}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U0123ABCD"},
+		"response_url": fakeSlack.URL,
+		"actions": []map[string]string{
+			{"action_id": "extend-day", "block_id": extendBlockID, "value": "test-1", "type": "button"},
+		},
+	})
+	assert.NilError(t, err)
+
+	// The request is answered while the sandbox is still being extended, and the extension outlives the request
+	ctx, cancel := context.WithCancel(context.Background())
+	req := signedRequest(t, signingSecret, url.Values{"payload": {string(payload)}}.Encode()).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	cancel()
+
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.Equal(t, len(extender.calls), 0)
+
+	close(extender.release)
+	handler.pending.Wait()
+	assert.DeepEqual(t, extender.calls, []string{"extend test-1 by 24h0m0s for U0123ABCD"})
+}
+
+func TestInteractionHandlerShutdown(t *testing.T) {
+	replies := make(chan string, 1)
+	fakeSlack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &slack.WebhookMessage{}
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(msg))
+		replies <- msg.Text
+	}))
+	defer fakeSlack.Close()
+
+	extender := &blockingExtender{release: make(chan struct{})}
+	handler := &InteractionHandler{Extender: extender, SigningSecret: signingSecret}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U0123ABCD"},
+		"response_url": fakeSlack.URL,
+		"actions": []map[string]string{
+			{"action_id": "extend-day", "block_id": extendBlockID, "value": "test-1", "type": "button"},
+		},
+	})
+	assert.NilError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, signedRequest(t, signingSecret, url.Values{"payload": {string(payload)}}.Encode()))
+	assert.Equal(t, w.Code, http.StatusOK)
+
+	// The shutdown does not finish while the sandbox is still being extended
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, handler.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(extender.release)
+	assert.NilError(t, handler.Shutdown(context.Background()))
+	assert.DeepEqual(t, extender.calls, []string{"extend test-1 by 24h0m0s for U0123ABCD"})
+	assert.Equal(t, <-replies, "Sandbox test-1 is extended")
+}
+
+func TestInteractionHandlerRejectsRequests(t *testing.T) {
+	var tests = map[string]struct {
+		secret        string
+		configuredFor string
+		body          string
+		status        int
+	}{
+		"Without a signing secret": {"", "", "payload={}", http.StatusUnauthorized},
+		"Too large":                {signingSecret, signingSecret, "payload=" + strings.Repeat("x", maxInteractionSize), http.StatusBadRequest},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			extender := &fakeExtender{}
+			handler := &InteractionHandler{Extender: extender, SigningSecret: data.configuredFor}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, signedRequest(t, data.secret, data.body))
+			handler.pending.Wait()
+
+			assert.Equal(t, w.Code, data.status)
+			assert.Equal(t, len(extender.calls), 0)
+		})
+	}
+}
+
+func TestNotifyWithExtend(t *testing.T) {
+	var form url.Values
+	fakeSlack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/chat.postMessage")
+		assert.NilError(t, r.ParseForm())
+		form = r.PostForm
+		fmt.Fprint(w, `{"ok": true, "channel": "C0123ABCD", "ts": "1614600000.000100"}`)
+	}))
+	defer fakeSlack.Close()
+
+	slacker := &Slacker{
+		client: slack.New("xoxb-token", slack.OptionAPIURL(fakeSlack.URL+"/")),
+		config: &Config{ChannelID: "C0123ABCD"},
+	}
+
+	assert.NilError(t, slacker.NotifyWithExtend("", "Sandbox test-1 expires soon", "test-1"))
+	assert.Equal(t, form.Get("channel"), "C0123ABCD")
+	assert.Equal(t, form.Get("text"), "Sandbox test-1 expires soon")
+
+	blocks := form.Get("blocks")
+	for _, action := range extendActions {
+		assert.Assert(t, strings.Contains(blocks, `"action_id":"`+action.id+`"`), blocks)
+	}
+	assert.Assert(t, strings.Contains(blocks, `"value":"test-1"`), blocks)
+}
+
+// signedRequest returns a request with the body, signed like Slack signs its requests
+func signedRequest(t *testing.T, secret string, body string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, err := mac.Write([]byte("v0:" + timestamp + ":" + body))
+	assert.NilError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
@@ -1,6 +1,8 @@
 package slack
 
 import (
+	"context"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/slack-go/slack"
 	"github.com/stackvista/sandbox-operator/internal/notification"
@@ -11,6 +13,7 @@ type Config struct {
 	ChannelID     string `split_words:"true" required:"false"`
 	PostAsUser    string `split_words:"true" required:"false"`
 	PostAsIconURL string `split_words:"true" required:"false"`
+	SigningSecret string `split_words:"true" required:"false"` // Verifies the interactions that Slack sends when a button is clicked
 }
 
 type Slacker struct {
@@ -18,7 +21,7 @@ type Slacker struct {
 	config *Config
 }
 
-var _ notification.Notifier = (*Slacker)(nil) // Compile-time check
+var _ notification.ExtendNotifier = (*Slacker)(nil) // Compile-time check
 
 func NewSlacker() (*Slacker, error) {
 	config := &Config{}
@@ -36,10 +39,31 @@ func NewSlacker() (*Slacker, error) {
 // if channelID is given, will post to the given channelID, else it will be posted
 // to the default channelID
 func (s *Slacker) Notify(channelID string, message string) error {
-	msgOpts := s.constructMsgOpts(message)
+	return s.post(channelID, s.constructMsgOpts(message)...)
+}
+
+// NotifyWithExtend posts a message with buttons to extend the Sandbox by a day or a week, or to keep it until it is
+// deleted manually. The clicks are handled by the InteractionHandler.
+func (s *Slacker) NotifyWithExtend(channelID string, message string, sandbox string) error {
+	text := slack.NewTextBlockObject(slack.MarkdownType, message, false, false)
+	buttons := []slack.BlockElement{}
+	for _, action := range extendActions {
+		buttons = append(buttons, slack.NewButtonBlockElement(action.id, sandbox,
+			slack.NewTextBlockObject(slack.PlainTextType, action.text, false, false)))
+	}
+
+	msgOpts := append(s.constructMsgOpts(message), slack.MsgOptionBlocks(
+		slack.NewSectionBlock(text, nil, nil),
+		slack.NewActionBlock(extendBlockID, buttons...),
+	))
 
+	return s.post(channelID, msgOpts...)
+}
+
+// post posts to the given channelID, or to the default channelID if none is given
+func (s *Slacker) post(channelID string, msgOpts ...slack.MsgOption) error {
 	channel := channelID
-	if channelID != "" {
+	if channelID == "" {
 		channel = s.config.ChannelID
 	}
 
@@ -66,3 +90,13 @@ func (s *Slacker) constructMsgOpts(message string) []slack.MsgOption {
 
 	return msgOpts
 }
+
+// LookupSlackId finds the Slack member ID of the user with the given email address.
+func (s *Slacker) LookupSlackId(ctx context.Context, email string) (string, error) {
+	user, err := s.client.GetUserByEmailContext(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
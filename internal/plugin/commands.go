@@ -0,0 +1,169 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+type createOptions struct {
+	user         string
+	slackId      string
+	ttl          time.Duration
+	manualExpiry bool
+	template     string
+	groups       []string
+	namespaces   []string
+}
+
+func (p *Plugin) createCommand() *cobra.Command {
+	opts := createOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a sandbox",
+		Long: "Create a sandbox. The user, Slack ID and expiration date are defaulted by the operator " +
+			"if they are not given.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.create(cmd.Context(), args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.user, "user", "", "The user to create the sandbox for")
+	cmd.Flags().StringVar(&opts.slackId, "slack-id", "", "The Slack member ID of the user")
+	cmd.Flags().DurationVar(&opts.ttl, "ttl", 0, "The time after which the sandbox expires")
+	cmd.Flags().BoolVar(&opts.manualExpiry, "manual-expiry", false, "Keep the sandbox once it has expired")
+	cmd.Flags().StringVar(&opts.template, "template", "", "The SandboxTemplate to provision the sandbox from")
+	cmd.Flags().StringSliceVar(&opts.groups, "group", nil, "Groups that are granted access to the sandbox")
+	cmd.Flags().StringSliceVar(&opts.namespaces, "namespace-suffix", nil, "Suffixes of the namespaces to provision")
+
+	return cmd
+}
+
+func (p *Plugin) create(ctx context.Context, name string, opts createOptions) error {
+	sb := &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: devopsv1.SandboxSpec{
+			User:         opts.user,
+			SlackId:      opts.slackId,
+			ManualExpiry: opts.manualExpiry,
+			Groups:       opts.groups,
+			Namespaces:   opts.namespaces,
+		},
+	}
+
+	if opts.ttl > 0 {
+		expiration := metav1.NewTime(clock.Ctx(ctx).Now().Add(opts.ttl))
+		sb.Spec.ExpirationDate = &expiration
+	}
+
+	if opts.template != "" {
+		sb.Spec.TemplateRef = &devopsv1.SandboxTemplateReference{Name: opts.template}
+	}
+
+	sb, err := p.Client.DevopsV1().Sandboxes().Create(ctx, sb, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "sandbox %s created for %s\n", sb.Name, sb.Spec.User)
+	return nil
+}
+
+func (p *Plugin) extendCommand() *cobra.Command {
+	var by time.Duration
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "extend NAME",
+		Short: "Extend the expiration date of a sandbox",
+		Long: "Extend the expiration date of a sandbox, either by a duration from the current expiration date, or " +
+			"until a given date. An expired sandbox is extended from now.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (by == 0) == (until == "") {
+				return fmt.Errorf("exactly one of --by and --until must be given")
+			}
+
+			var untilDate *time.Time
+			if until != "" {
+				t, err := time.Parse(time.RFC3339, until)
+				if err != nil {
+					return fmt.Errorf("invalid --until date: %v", err)
+				}
+				untilDate = &t
+			}
+
+			return p.extend(cmd.Context(), args[0], by, untilDate)
+		},
+	}
+
+	cmd.Flags().DurationVar(&by, "by", 0, "The duration to extend the sandbox with, e.g. 24h")
+	cmd.Flags().StringVar(&until, "until", "", "The RFC3339 date to extend the sandbox until")
+
+	return cmd
+}
+
+// extend sets the expiration date of the Sandbox to until, or moves it by the given duration. The duration is added
+// to the current expiration date, or to now if the Sandbox has already expired or never expires.
+func (p *Plugin) extend(ctx context.Context, name string, by time.Duration, until *time.Time) error {
+	sandboxes := p.Client.DevopsV1().Sandboxes()
+
+	// The new expiration date is calculated again from the latest Sandbox when it was changed in the meantime
+	var expiration metav1.Time
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sb, err := sandboxes.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		expiration = metav1.NewTime(extendedDate(ctx, sb, by, until))
+		sb.Spec.ExpirationDate = &expiration
+
+		_, err = sandboxes.Update(ctx, sb, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "sandbox %s extended until %s\n", name, formatDate(&expiration.Time))
+	return nil
+}
+
+// extendedDate returns until if it is given, or the current expiration date of the Sandbox moved by the duration.
+func extendedDate(ctx context.Context, sb *devopsv1.Sandbox, by time.Duration, until *time.Time) time.Time {
+	if until != nil {
+		return *until
+	}
+
+	base := clock.Ctx(ctx).Now()
+	if current := expirationDate(sb); current != nil && current.After(base) {
+		base = *current
+	}
+
+	return base.Add(by)
+}
+
+func (p *Plugin) deleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a sandbox and its namespaces",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := p.Client.DevopsV1().Sandboxes().Delete(cmd.Context(), args[0], metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(p.Out, "sandbox %s deleted\n", args[0])
+			return nil
+		},
+	}
+}
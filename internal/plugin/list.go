@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (p *Plugin) listCommand() *cobra.Command {
+	var user string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List sandboxes with their owner, namespace and expiry",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.list(cmd.Context(), user)
+		},
+	}
+
+	cmd.Flags().StringVarP(&user, "user", "u", "", "Only list the sandboxes of this user")
+
+	return cmd
+}
+
+func (p *Plugin) list(ctx context.Context, user string) error {
+	sandboxes, err := p.Client.DevopsV1().Sandboxes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.Out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tOWNER\tNAMESPACE\tEXPIRES\tTIME LEFT\tPHASE")
+	for i := range sandboxes.Items {
+		sb := &sandboxes.Items[i]
+		if user != "" && sb.Spec.User != user {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", sb.Name, sb.Spec.User, orDash(sb.Status.Namespace),
+			formatDate(expirationDate(sb)), timeLeft(ctx, sb), orDash(string(sb.Status.Phase)))
+	}
+
+	return w.Flush()
+}
+
+func (p *Plugin) describeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show the details of a sandbox",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.describe(cmd.Context(), args[0])
+		},
+	}
+}
+
+func (p *Plugin) describe(ctx context.Context, name string) error {
+	sb, err := p.Client.DevopsV1().Sandboxes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.Out, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", sb.Name)
+	fmt.Fprintf(w, "Owner:\t%s\n", sb.Spec.User)
+	fmt.Fprintf(w, "Slack ID:\t%s\n", orDash(sb.Spec.SlackId))
+	if sb.Spec.TemplateRef != nil {
+		fmt.Fprintf(w, "Template:\t%s\n", sb.Spec.TemplateRef.Name)
+	}
+	if len(sb.Spec.Groups) > 0 {
+		fmt.Fprintf(w, "Groups:\t%s\n", strings.Join(sb.Spec.Groups, ", "))
+	}
+	fmt.Fprintf(w, "Phase:\t%s\n", orDash(string(sb.Status.Phase)))
+	fmt.Fprintf(w, "Expires:\t%s\n", formatDate(expirationDate(sb)))
+	fmt.Fprintf(w, "Time left:\t%s\n", timeLeft(ctx, sb))
+	fmt.Fprintf(w, "Manual expiry:\t%t\n", sb.Spec.ManualExpiry)
+	if uptime := sb.Spec.Uptime; uptime != nil {
+		fmt.Fprintf(w, "Uptime:\t%s %s-%s %s\n", orDash(uptime.Days), uptime.Start, uptime.End, orDash(uptime.TimeZone))
+	}
+	fmt.Fprintf(w, "Created:\t%s\n", formatDate(&sb.CreationTimestamp.Time))
+	if sb.Status.LastActivity != nil {
+		fmt.Fprintf(w, "Last activity:\t%s\n", formatDate(&sb.Status.LastActivity.Time))
+	}
+
+	if len(sb.Status.Namespaces) > 0 {
+		fmt.Fprintln(w, "Namespaces:")
+		for _, ns := range sb.Status.Namespaces {
+			fmt.Fprintf(w, "  %s\t%s\n", ns.Name, orDash(string(ns.Phase)))
+		}
+	} else {
+		fmt.Fprintf(w, "Namespace:\t%s\n", orDash(sb.Status.Namespace))
+	}
+
+	if len(sb.Status.Conditions) > 0 {
+		fmt.Fprintln(w, "Conditions:")
+		for _, c := range sb.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.Message)
+		}
+	}
+
+	if sb.Status.Teardown != nil {
+		fmt.Fprintf(w, "Teardown:\t%s\t%s\n", sb.Status.Teardown.Step, sb.Status.Teardown.Message)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Plugin implements the kubectl-sandbox commands on top of the Sandbox clientset
+type Plugin struct {
+	Client       versioned.Interface
+	ClientConfig clientcmd.ClientConfig
+	// Context is the kubeconfig context given on the command line, if any
+	Context string
+	Out     io.Writer
+}
+
+// PluginCommand returns the root command of the kubectl-sandbox plugin
+func PluginCommand() *cobra.Command {
+	p := &Plugin{Out: os.Stdout}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+
+	cmd := &cobra.Command{
+		Use:          "kubectl-sandbox",
+		Short:        "Manage StackState sandboxes",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			p.Context = overrides.CurrentContext
+			p.ClientConfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+			config, err := p.ClientConfig.ClientConfig()
+			if err != nil {
+				return err
+			}
+
+			client, err := versioned.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			p.Client = client
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&loadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file to use")
+	cmd.PersistentFlags().StringVar(&overrides.CurrentContext, "context", "", "The name of the kubeconfig context to use")
+
+	cmd.AddCommand(p.createCommand())
+	cmd.AddCommand(p.listCommand())
+	cmd.AddCommand(p.describeCommand())
+	cmd.AddCommand(p.extendCommand())
+	cmd.AddCommand(p.deleteCommand())
+	cmd.AddCommand(p.useCommand())
+
+	return cmd
+}
+
+// expirationDate returns the expiration date in the spec of the Sandbox, or the effective one as reported by the reaper
+// if none is set. The status can lag behind a recent change of the spec.
+func expirationDate(sandbox *devopsv1.Sandbox) *time.Time {
+	if sandbox.Spec.ExpirationDate != nil {
+		return &sandbox.Spec.ExpirationDate.Time
+	}
+
+	if sandbox.Status.ExpirationDate != nil {
+		return &sandbox.Status.ExpirationDate.Time
+	}
+
+	return nil
+}
+
+// timeLeft formats the time until the Sandbox expires
+func timeLeft(ctx context.Context, sandbox *devopsv1.Sandbox) string {
+	expiration := expirationDate(sandbox)
+	if expiration == nil {
+		return "-"
+	}
+
+	left := expiration.Sub(clock.Ctx(ctx).Now())
+	if left <= 0 {
+		if sandbox.Spec.ManualExpiry {
+			return fmt.Sprintf("overdue %s", duration.HumanDuration(-left))
+		}
+		return "expired"
+	}
+
+	return duration.HumanDuration(left)
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned/fake"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestList(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	expires := metav1.NewTime(c.Now().Add(50 * time.Hour))
+	expired := metav1.NewTime(c.Now().Add(-3 * time.Hour))
+
+	p, out := newTestPlugin(t,
+		&devopsv1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+			Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+			Status: devopsv1.SandboxStatus{
+				Namespace:      "sandbox-jdoe-test-1",
+				ExpirationDate: &expires,
+				Phase:          devopsv1.SandboxReady,
+			},
+		},
+		&devopsv1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-2"},
+			Spec:       devopsv1.SandboxSpec{User: "asmith", ExpirationDate: &expired, ManualExpiry: true},
+		},
+	)
+
+	assert.NilError(t, p.list(ctx, ""))
+	assert.Equal(t, out.String(), ""+
+		"NAME     OWNER    NAMESPACE             EXPIRES                TIME LEFT    PHASE\n"+
+		"test-1   jdoe     sandbox-jdoe-test-1   2021-03-03T14:00:00Z   2d2h         Ready\n"+
+		"test-2   asmith   -                     2021-03-01T09:00:00Z   overdue 3h   -\n")
+
+	out.Reset()
+	assert.NilError(t, p.list(ctx, "asmith"))
+	assert.Equal(t, bytes.Count(out.Bytes(), []byte("\n")), 2)
+}
+
+func TestExtend(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	until := c.Now().Add(72 * time.Hour)
+
+	var tests = map[string]struct {
+		expiration *time.Time
+		status     *time.Time
+		by         time.Duration
+		until      *time.Time
+		expected   time.Time
+	}{
+		"Extend from expiration date":    {pTime(c.Now().Add(time.Hour)), nil, 24 * time.Hour, nil, c.Now().Add(25 * time.Hour)},
+		"Extend expired sandbox":         {pTime(c.Now().Add(-time.Hour)), nil, 24 * time.Hour, nil, c.Now().Add(24 * time.Hour)},
+		"Extend without expiration":      {nil, nil, 24 * time.Hour, nil, c.Now().Add(24 * time.Hour)},
+		"Extend from default expiration": {nil, pTime(c.Now().Add(2 * time.Hour)), 24 * time.Hour, nil, c.Now().Add(26 * time.Hour)},
+		"Extend from edited expiration":  {pTime(c.Now().Add(10 * time.Hour)), pTime(c.Now().Add(time.Hour)), 24 * time.Hour, nil, c.Now().Add(34 * time.Hour)},
+		"Extend until date":              {pTime(c.Now().Add(time.Hour)), nil, 0, &until, until},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+				Spec:       devopsv1.SandboxSpec{User: "jdoe"},
+			}
+			if data.expiration != nil {
+				sandbox.Spec.ExpirationDate = &metav1.Time{Time: *data.expiration}
+			}
+			if data.status != nil {
+				sandbox.Status.ExpirationDate = &metav1.Time{Time: *data.status}
+			}
+
+			p, _ := newTestPlugin(t, sandbox)
+			assert.NilError(t, p.extend(ctx, sandbox.Name, data.by, data.until))
+
+			updated, err := p.Client.DevopsV1().Sandboxes().Get(ctx, sandbox.Name, metav1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Assert(t, updated.Spec.ExpirationDate.Time.Equal(data.expected))
+		})
+	}
+}
+
+func TestExtendRetriesOnConflict(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	expiration := metav1.NewTime(c.Now().Add(time.Hour))
+	p, out := newTestPlugin(t, &devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1"},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe", ExpirationDate: &expiration},
+	})
+
+	// The Sandbox is extended by someone else just before the first update
+	client := p.Client.(*fake.Clientset)
+	conflicted := false
+	client.PrependReactor("update", "sandboxes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicted {
+			return false, nil, nil
+		}
+		conflicted = true
+
+		sb, err := client.Tracker().Get(action.GetResource(), "", "test-1")
+		assert.NilError(t, err)
+		edited := sb.(*devopsv1.Sandbox).DeepCopy()
+		edited.Spec.ExpirationDate = &metav1.Time{Time: c.Now().Add(10 * time.Hour)}
+		assert.NilError(t, client.Tracker().Update(action.GetResource(), edited, ""))
+
+		return true, nil, errors.NewConflict(action.GetResource().GroupResource(), "test-1", fmt.Errorf("modified"))
+	})
+
+	assert.NilError(t, p.extend(ctx, "test-1", 24*time.Hour, nil))
+
+	updated, err := p.Client.DevopsV1().Sandboxes().Get(ctx, "test-1", metav1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, updated.Spec.ExpirationDate.Time.Equal(c.Now().Add(34*time.Hour)))
+	assert.Equal(t, out.String(), "sandbox test-1 extended until 2021-03-02T22:00:00Z\n")
+}
+
+// newTestPlugin returns a Plugin with a fake client that contains the given sandboxes. They are created through the
+// client, as the object tracker of the fake clientset guesses the wrong resource name for Sandboxes.
+func newTestPlugin(t *testing.T, sandboxes ...*devopsv1.Sandbox) (*Plugin, *bytes.Buffer) {
+	client := fake.NewSimpleClientset()
+	for _, sb := range sandboxes {
+		_, err := client.DevopsV1().Sandboxes().Create(context.Background(), sb, metav1.CreateOptions{})
+		assert.NilError(t, err)
+	}
+
+	out := &bytes.Buffer{}
+	return &Plugin{Client: client, Out: out}, out
+}
+
+func pTime(t time.Time) *time.Time {
+	return &t
+}
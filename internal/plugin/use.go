@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func (p *Plugin) useCommand() *cobra.Command {
+	var suffix string
+
+	cmd := &cobra.Command{
+		Use:   "use NAME",
+		Short: "Switch the namespace of the current kubeconfig context to the sandbox",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, err := p.sandboxNamespace(cmd.Context(), args[0], suffix)
+			if err != nil {
+				return err
+			}
+
+			config, err := p.ClientConfig.RawConfig()
+			if err != nil {
+				return err
+			}
+
+			if err := useNamespace(&config, p.ClientConfig.ConfigAccess(), p.Context, namespace); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(p.Out, "switched to namespace %s\n", namespace)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&suffix, "namespace-suffix", "", "The namespace of the sandbox to use, if it has several")
+
+	return cmd
+}
+
+// sandboxNamespace returns the name of the namespace of the Sandbox with the given suffix, or its primary namespace
+func (p *Plugin) sandboxNamespace(ctx context.Context, name string, suffix string) (string, error) {
+	sb, err := p.Client.DevopsV1().Sandboxes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	if suffix == "" {
+		if sb.Status.Namespace != "" {
+			return sb.Status.Namespace, nil
+		}
+		return pkgsandbox.NamespaceNames(sb)[0], nil
+	}
+
+	for _, s := range sb.Spec.Namespaces {
+		if s == suffix {
+			return fmt.Sprintf("%s-%s", pkgsandbox.SandboxName(sb), suffix), nil
+		}
+	}
+
+	return "", fmt.Errorf("sandbox %s has no namespace with suffix %s", name, suffix)
+}
+
+// useNamespace sets the namespace of the given context, or the current context if none is given, and writes the
+// kubeconfig
+func useNamespace(config *clientcmdapi.Config, access clientcmd.ConfigAccess, contextName string, namespace string) error {
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return fmt.Errorf("kubeconfig context %q does not exist", contextName)
+	}
+
+	kubeContext.Namespace = namespace
+	return clientcmd.ModifyConfig(access, *config, true)
+}
@@ -0,0 +1,57 @@
+package reaper
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=replicasets;controllerrevisions,verbs=get;list
+
+// Controller runs the Reaper continuously in the operator. Every Sandbox is reaped when it is created or its spec
+// changes, and requeued at the next moment that it has to be reaped or its owner has to be warned.
+type Controller struct {
+	Reaper *Reaper
+	Logger *zerolog.Logger
+}
+
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = c.Logger.WithContext(ctx)
+
+	// The Sandbox is read from the API server instead of the cache, so that a notification that was just recorded in
+	// its status is never sent twice
+	sb, err := c.Reaper.sandboxClient.DevopsV1().Sandboxes().Get(ctx, req.Name, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	deleted, err := c.Reaper.reap(ctx, sb)
+	if err != nil || deleted {
+		return ctrl.Result{}, err
+	}
+
+	next := c.Reaper.nextCheck(ctx, *sb)
+	if next.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: next.Sub(clock.Ctx(ctx).Now())}, nil
+}
+
+func (c *Controller) SetupWithManager(mgr ctrl.Manager) error {
+	// The Reaper only updates the status, which does not change the generation, so its own updates are not reaped again
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("reaper").
+		For(&devopsv1.Sandbox{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(c)
+}
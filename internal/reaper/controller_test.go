@@ -0,0 +1,80 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/rs/zerolog"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/internal/notification"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned/fake"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestController(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	sandbox := newSandbox(c, 0, pDuration(5*Day), false)
+	sandbox.Name = "test-1"
+
+	client := fake.NewSimpleClientset()
+	_, err := client.DevopsV1().Sandboxes().Create(ctx, &sandbox, v1.CreateOptions{})
+	assert.NilError(t, err)
+
+	notifier := notification.NewMock()
+	logger := zerolog.Nop()
+	controller := &Controller{
+		Reaper: &Reaper{
+			sandboxClient: client,
+			config: &Config{
+				FirstExpirationWarning:   3 * Day,
+				WarningInterval:          1 * Day,
+				ExpirationWarningMessage: "Sandbox {{ .Sandbox.Name }} expires soon",
+				ReapMessage:              "Sandbox {{ .Sandbox.Name }} is deleted",
+			},
+			notifier: notifier,
+		},
+		Logger: &logger,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name}}
+
+	// The Sandbox is requeued when its owner has to be warned
+	result, err := controller.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, 2*Day)
+	assert.Equal(t, len(notifier.Notifications), 0)
+
+	// Once warned, the Sandbox is requeued at the next warning
+	c.Add(result.RequeueAfter)
+	result, err = controller.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, 1*Day)
+	assert.Equal(t, len(notifier.Notifications), 1)
+
+	// A reconcile in between does not warn again
+	result, err = controller.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result.RequeueAfter, 1*Day)
+	assert.Equal(t, len(notifier.Notifications), 1)
+
+	// Right after its expiration date the Sandbox is reaped
+	c.Add(3*Day + time.Nanosecond)
+	result, err = controller.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+	assert.Equal(t, len(notifier.Notifications), 2)
+
+	_, err = client.DevopsV1().Sandboxes().Get(ctx, sandbox.Name, v1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err))
+
+	result, err = controller.Reconcile(ctx, req)
+	assert.NilError(t, err)
+	assert.Equal(t, result, ctrl.Result{})
+}
@@ -0,0 +1,94 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/internal/notification/slack"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ slack.SandboxExtender = (*Reaper)(nil) // Compile-time check
+
+// ExtendSandbox moves the expiration date of the Sandbox by the given duration, starting from now if the Sandbox is
+// already past its expiration date. The expiration date is capped at the maximum lifetime of the Sandbox.
+func (r *Reaper) ExtendSandbox(ctx context.Context, name string, slackId string, by time.Duration) (string, error) {
+	return r.updateExpiry(ctx, name, slackId, func(sb *devopsv1.Sandbox) string {
+		now := clock.Ctx(ctx).Now()
+		expirationDate := r.expirationDate(ctx, *sb)
+		if expirationDate.Before(now) {
+			expirationDate = now
+		}
+		expirationDate = expirationDate.Add(by)
+
+		capped := false
+		if r.config.MaxLifetime > 0 {
+			if maxDate := sb.CreationTimestamp.Add(r.config.MaxLifetime); expirationDate.After(maxDate) {
+				expirationDate, capped = maxDate, true
+			}
+		}
+
+		if !expirationDate.After(r.expirationDate(ctx, *sb)) {
+			return fmt.Sprintf("Sandbox %s can not be extended, it has reached its maximum lifetime of %s and expires at %s.",
+				sb.Name, r.config.MaxLifetime, r.expirationDate(ctx, *sb).Format(time.RFC3339))
+		}
+
+		sb.Spec.ExpirationDate = &v1.Time{Time: expirationDate}
+		if capped {
+			return fmt.Sprintf("Sandbox %s is extended to its maximum lifetime of %s, it now expires at %s.",
+				sb.Name, r.config.MaxLifetime, expirationDate.Format(time.RFC3339))
+		}
+
+		return fmt.Sprintf("Sandbox %s is extended, it now expires at %s.", sb.Name, expirationDate.Format(time.RFC3339))
+	})
+}
+
+// KeepSandbox sets manual expiry on the Sandbox, so that it is not reaped until its owner deletes it. When the lifetime
+// of sandboxes is limited, the Sandbox is extended to its maximum lifetime instead, as the webhook does not allow manual
+// expiry then.
+func (r *Reaper) KeepSandbox(ctx context.Context, name string, slackId string) (string, error) {
+	if r.config.MaxLifetime > 0 {
+		return r.ExtendSandbox(ctx, name, slackId, r.config.MaxLifetime)
+	}
+
+	return r.updateExpiry(ctx, name, slackId, func(sb *devopsv1.Sandbox) string {
+		sb.Spec.ManualExpiry = true
+		return fmt.Sprintf("Sandbox %s is kept until you delete it, you will be reminded when it is overdue.", sb.Name)
+	})
+}
+
+// updateExpiry applies the change to the Sandbox, if the Slack user is its owner, and returns the reply for the user.
+func (r *Reaper) updateExpiry(ctx context.Context, name string, slackId string, change func(sb *devopsv1.Sandbox) string) (string, error) {
+	reply := ""
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		sb, err := r.sandboxClient.DevopsV1().Sandboxes().Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if sb.Spec.SlackId != slackId {
+			reply = fmt.Sprintf("Only the owner of sandbox %s can change its expiry.", name)
+			return nil
+		}
+
+		original := sb.Spec.DeepCopy()
+		reply = change(sb)
+		if sb.Spec.ManualExpiry == original.ManualExpiry && sb.Spec.ExpirationDate.Equal(original.ExpirationDate) {
+			return nil
+		}
+
+		_, err = r.sandboxClient.DevopsV1().Sandboxes().Update(ctx, sb, v1.UpdateOptions{})
+		return err
+	})
+
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("Sandbox %s no longer exists.", name), nil
+	}
+
+	return reply, err
+}
@@ -0,0 +1,102 @@
+package reaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned/fake"
+	"gotest.tools/v3/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExtendSandbox(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	var tests = map[string]struct {
+		createdAgo time.Duration
+		expiration time.Duration
+		slackId    string
+		by         time.Duration
+		expected   time.Duration
+		reply      string
+	}{
+		"Extend":                   {-1 * Day, 1 * Day, "U0123ABCD", 7 * Day, 8 * Day, "Sandbox test-1 is extended, it now expires at 2021-03-09T12:00:00Z."},
+		"Extend expired sandbox":   {-3 * Day, -1 * Day, "U0123ABCD", 1 * Day, 1 * Day, "Sandbox test-1 is extended, it now expires at 2021-03-02T12:00:00Z."},
+		"Capped at max lifetime":   {-25 * Day, 3 * Day, "U0123ABCD", 7 * Day, 5 * Day, "Sandbox test-1 is extended to its maximum lifetime of 720h0m0s, it now expires at 2021-03-06T12:00:00Z."},
+		"At max lifetime":          {-25 * Day, 5 * Day, "U0123ABCD", 1 * Day, 5 * Day, "Sandbox test-1 can not be extended, it has reached its maximum lifetime of 720h0m0s and expires at 2021-03-06T12:00:00Z."},
+		"Extended by someone else": {-1 * Day, 1 * Day, "U9999ZZZZ", 7 * Day, 1 * Day, "Only the owner of sandbox test-1 can change its expiry."},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			reaper, client := newExtendTestReaper(t, c, data.createdAgo, data.expiration)
+
+			reply, err := reaper.ExtendSandbox(ctx, "test-1", data.slackId, data.by)
+			assert.NilError(t, err)
+			assert.Equal(t, reply, data.reply)
+
+			sb, err := client.DevopsV1().Sandboxes().Get(ctx, "test-1", v1.GetOptions{})
+			assert.NilError(t, err)
+			assert.Assert(t, sb.Spec.ExpirationDate.Time.Equal(c.Now().Add(data.expected)), sb.Spec.ExpirationDate)
+		})
+	}
+}
+
+func TestKeepSandbox(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	reaper, client := newExtendTestReaper(t, c, -1*Day, 1*Day)
+	reaper.config.MaxLifetime = 0
+
+	reply, err := reaper.KeepSandbox(ctx, "test-1", "U0123ABCD")
+	assert.NilError(t, err)
+	assert.Equal(t, reply, "Sandbox test-1 is kept until you delete it, you will be reminded when it is overdue.")
+
+	sb, err := client.DevopsV1().Sandboxes().Get(ctx, "test-1", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, sb.Spec.ManualExpiry)
+
+	reply, err = reaper.KeepSandbox(ctx, "test-2", "U0123ABCD")
+	assert.NilError(t, err)
+	assert.Equal(t, reply, "Sandbox test-2 no longer exists.")
+}
+
+func TestKeepSandboxWithMaxLifetime(t *testing.T) {
+	c := clk.NewMock()
+	c.Set(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	ctx := clock.WithContext(context.Background(), c)
+
+	// The sandbox is only kept until its maximum lifetime, manual expiry would keep it beyond that
+	reaper, client := newExtendTestReaper(t, c, -1*Day, 1*Day)
+
+	reply, err := reaper.KeepSandbox(ctx, "test-1", "U0123ABCD")
+	assert.NilError(t, err)
+	assert.Equal(t, reply, "Sandbox test-1 is extended to its maximum lifetime of 720h0m0s, it now expires at 2021-03-30T12:00:00Z.")
+
+	sb, err := client.DevopsV1().Sandboxes().Get(ctx, "test-1", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !sb.Spec.ManualExpiry)
+	assert.Assert(t, sb.Spec.ExpirationDate.Time.Equal(c.Now().Add(29*Day)), sb.Spec.ExpirationDate)
+}
+
+func newExtendTestReaper(t *testing.T, c clk.Clock, createdAgo time.Duration, expiration time.Duration) (*Reaper, *fake.Clientset) {
+	sb := newSandbox(c, createdAgo, &expiration, false)
+	sb.Name = "test-1"
+	sb.Spec.SlackId = "U0123ABCD"
+
+	client := fake.NewSimpleClientset()
+	_, err := client.DevopsV1().Sandboxes().Create(context.Background(), &sb, v1.CreateOptions{})
+	assert.NilError(t, err)
+
+	return &Reaper{
+		sandboxClient: client,
+		config:        &Config{MaxLifetime: 30 * Day},
+	}, client
+}
@@ -0,0 +1,212 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ActionType is what the Reaper does with a Sandbox
+type ActionType string
+
+const (
+	// ActionDelete deletes the Sandbox and notifies its owner
+	ActionDelete ActionType = "delete"
+	// ActionWarn warns the owner that the Sandbox will be deleted
+	ActionWarn ActionType = "warn"
+	// ActionOverdue notifies the owner that the Sandbox with manual expiry is past its expiration date
+	ActionOverdue ActionType = "overdue"
+	// ActionNone leaves the Sandbox as it is, it is only part of a plan
+	ActionNone ActionType = "none"
+)
+
+// ActionReason is why the Reaper takes an action on a Sandbox
+type ActionReason string
+
+const (
+	// ReasonExpired means the Sandbox is past its expiration date
+	ReasonExpired ActionReason = "Expired"
+	// ReasonIdle means the Sandbox has been idle for longer than the idle timeout
+	ReasonIdle ActionReason = "Idle"
+	// ReasonExpirationImminent means the Sandbox will soon be deleted, as it nears its expiration date
+	ReasonExpirationImminent ActionReason = "ExpirationImminent"
+	// ReasonExpirationOverdue means the Sandbox with manual expiry is past its expiration date
+	ReasonExpirationOverdue ActionReason = "ExpirationOverdue"
+	// ReasonIdleReapImminent means the Sandbox will soon be deleted, as it nears the idle timeout
+	ReasonIdleReapImminent ActionReason = "IdleReapImminent"
+)
+
+// maxTableMessageLength is the number of characters of a message that is shown in a table
+const maxTableMessageLength = 80
+
+// Action is what the Reaper does with a Sandbox, with the message that its owner is notified with
+type Action struct {
+	Sandbox        string       `json:"sandbox"`
+	User           string       `json:"user"`
+	Action         ActionType   `json:"action"`
+	Reason         ActionReason `json:"reason"`
+	ExpirationDate time.Time    `json:"expirationDate"`
+	Message        string       `json:"message"`
+	// Checks are the outcomes of the checks that the action is based on, they are only filled in by Plan
+	Checks Checks `json:"checks"`
+}
+
+// Checks are the outcomes of the checks that the Reaper evaluates for a Sandbox
+type Checks struct {
+	Expired            bool `json:"expired"`
+	ExpirationImminent bool `json:"expirationImminent"`
+	ExpirationOverdue  bool `json:"expirationOverdue"`
+	Idle               bool `json:"idle"`
+	IdleImminent       bool `json:"idleImminent"`
+	Notify             bool `json:"notify"`
+	NotifyIdle         bool `json:"notifyIdle"`
+}
+
+// String returns the names of the checks that passed, or - if none did
+func (c Checks) String() string {
+	names := []string{}
+	for _, check := range []struct {
+		name   string
+		passed bool
+	}{
+		{"expired", c.Expired},
+		{"expirationImminent", c.ExpirationImminent},
+		{"expirationOverdue", c.ExpirationOverdue},
+		{"idle", c.Idle},
+		{"idleImminent", c.IdleImminent},
+		{"notify", c.Notify},
+		{"notifyIdle", c.NotifyIdle},
+	} {
+		if check.passed {
+			names = append(names, check.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+func (r *Reaper) newAction(ctx context.Context, sb devopsv1.Sandbox, action ActionType, reason ActionReason, message string) (*Action, error) {
+	msg, err := r.constructMessage(ctx, message, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Action{
+		Sandbox:        sb.Name,
+		User:           sb.Spec.User,
+		Action:         action,
+		Reason:         reason,
+		ExpirationDate: r.expirationDate(ctx, sb),
+		Message:        msg,
+	}, nil
+}
+
+// Plan returns the actions that Run would take, without deleting sandboxes, notifying their owners or updating their
+// status. Sandboxes that are left as they are get an action of type none, so that every Sandbox is in the plan with
+// the outcomes of its checks.
+func (r *Reaper) Plan(ctx context.Context) ([]Action, error) {
+	sandboxes, err := r.sandboxClient.DevopsV1().Sandboxes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []Action{}
+	for i := range sandboxes.Items {
+		sb := &sandboxes.Items[i]
+		if !sb.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		log.Ctx(ctx).Debug().Str("sandbox", sb.Name).Msg("Planning Sandbox")
+		action, err := r.planAction(ctx, sb)
+		if err != nil {
+			return nil, err
+		}
+
+		if action == nil {
+			action = &Action{
+				Sandbox:        sb.Name,
+				User:           sb.Spec.User,
+				Action:         ActionNone,
+				ExpirationDate: r.expirationDate(ctx, *sb),
+			}
+		}
+		action.Checks = r.evaluate(ctx, *sb)
+		actions = append(actions, *action)
+	}
+
+	return actions, nil
+}
+
+// evaluate runs all checks of the Reaper on the Sandbox, the last activity must already be recorded in its status.
+func (r *Reaper) evaluate(ctx context.Context, sb devopsv1.Sandbox) Checks {
+	return Checks{
+		Expired:            r.isExpired(ctx, sb),
+		ExpirationImminent: r.isExpirationImminent(ctx, sb),
+		ExpirationOverdue:  r.isExpirationOverdue(ctx, sb),
+		Idle:               r.isIdle(ctx, sb),
+		IdleImminent:       r.isIdleImminent(ctx, sb),
+		Notify:             r.shouldNotify(ctx, sb),
+		NotifyIdle:         r.shouldNotifyIdle(ctx, sb),
+	}
+}
+
+// WritePlan writes the actions to w as a table, json or yaml
+func WritePlan(w io.Writer, actions []Action, format string) error {
+	switch format {
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(tw, "SANDBOX\tUSER\tACTION\tREASON\tEXPIRES\tCHECKS\tMESSAGE")
+		for _, a := range actions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.Sandbox, a.User, a.Action, orNone(string(a.Reason)),
+				a.ExpirationDate.Format(time.RFC3339), a.Checks, tableMessage(a.Message))
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(actions)
+	case "yaml":
+		out, err := yaml.Marshal(actions)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+	}
+}
+
+// tableMessage fits a message on a single line of a table, by escaping the line breaks and tabs and truncating it.
+func tableMessage(message string) string {
+	if message == "" {
+		return "-"
+	}
+
+	message = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`).Replace(message)
+	if runes := []rune(message); len(runes) > maxTableMessageLength {
+		message = string(runes[:maxTableMessageLength-3]) + "..."
+	}
+
+	return message
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned/fake"
+	"gotest.tools/v3/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlan(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	client := fake.NewSimpleClientset()
+	for name, sandbox := range map[string]struct {
+		expiration   *time.Duration
+		manualExpiry bool
+	}{
+		"expired":  {pDuration(-1 * Day), false},
+		"expiring": {pDuration(1 * Day), false},
+		"overdue":  {pDuration(-1 * Day), true},
+		"fine":     {pDuration(7 * Day), false},
+	} {
+		sb := newSandbox(c, -10*Day, sandbox.expiration, sandbox.manualExpiry)
+		sb.Name = name
+		sb.Spec.User = "jdoe"
+		_, err := client.DevopsV1().Sandboxes().Create(ctx, &sb, v1.CreateOptions{})
+		assert.NilError(t, err)
+	}
+
+	// Without a notifier, any attempt to notify fails the test
+	reaper := &Reaper{
+		sandboxClient: client,
+		config: &Config{
+			FirstExpirationWarning:   3 * Day,
+			WarningInterval:          1 * Day,
+			ExpirationWarningMessage: "Sandbox {{ .Sandbox.Name }} expires soon.\n\tExtend it with a longer expiration date if you still need it, or delete it yourself.",
+			ReapMessage:              "Sandbox {{ .Sandbox.Name }} is deleted",
+			ExpirationOverdueMessage: "Sandbox {{ .Sandbox.Name }} is overdue",
+		},
+	}
+
+	actions, err := reaper.Plan(ctx)
+	assert.NilError(t, err)
+
+	out := &bytes.Buffer{}
+	assert.NilError(t, WritePlan(out, actions, "table"))
+	// Every sandbox is in the plan, and messages are kept on a single line
+	assert.Equal(t, out.String(), ""+
+		"SANDBOX    USER   ACTION    REASON               EXPIRES                CHECKS                              MESSAGE\n"+
+		"expired    jdoe   delete    Expired              1969-12-31T00:00:00Z   expired,expirationImminent,notify   Sandbox expired is deleted\n"+
+		"expiring   jdoe   warn      ExpirationImminent   1970-01-02T00:00:00Z   expirationImminent,notify           Sandbox expiring expires soon.\\n\\tExtend it with a longer expiration date if ...\n"+
+		"fine       jdoe   none      -                    1970-01-08T00:00:00Z   -                                   -\n"+
+		"overdue    jdoe   overdue   ExpirationOverdue    1969-12-31T00:00:00Z   expirationOverdue,notify            Sandbox overdue is overdue\n")
+
+	// Nothing is deleted or recorded in the status
+	sandboxes, err := client.DevopsV1().Sandboxes().List(ctx, v1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(sandboxes.Items), 4)
+	for _, sb := range sandboxes.Items {
+		assert.Assert(t, sb.Status.LastNotification == nil, sb.Name)
+	}
+
+	// The full message is kept in the other formats
+	out.Reset()
+	assert.NilError(t, WritePlan(out, actions[1:2], "json"))
+	assert.Assert(t, strings.Contains(out.String(), `"message": "Sandbox expiring expires soon.\n\tExtend it with a longer expiration date if you still need it, or delete it yourself."`), out.String())
+
+	out.Reset()
+	assert.NilError(t, WritePlan(out, actions[:1], "yaml"))
+	assert.Equal(t, out.String(), ""+
+		"- action: delete\n"+
+		"  checks:\n"+
+		"    expirationImminent: true\n"+
+		"    expirationOverdue: false\n"+
+		"    expired: true\n"+
+		"    idle: false\n"+
+		"    idleImminent: false\n"+
+		"    notify: true\n"+
+		"    notifyIdle: false\n"+
+		"  expirationDate: \"1969-12-31T00:00:00Z\"\n"+
+		"  message: Sandbox expired is deleted\n"+
+		"  reason: Expired\n"+
+		"  sandbox: expired\n"+
+		"  user: jdoe\n")
+
+	assert.ErrorContains(t, WritePlan(out, actions, "xml"), "unknown output format")
+}
@@ -3,6 +3,7 @@ package reaper
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"text/template"
 	"time"
 
@@ -13,7 +14,12 @@ import (
 	"github.com/rs/zerolog/log"
 	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
 	"github.com/stackvista/sandbox-operator/pkg/client/versioned"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -25,18 +31,22 @@ type Config struct {
 	ExpirationWarningMessage string        `split_words:"true" required:"true"`
 	ReapMessage              string        `split_words:"true" required:"true"`
 	ExpirationOverdueMessage string        `split_words:"true" required:"true"`
+	IdleTimeout              time.Duration `split_words:"true" default:"0"`   // Default no idle reaping
+	IdleWarning              time.Duration `split_words:"true" default:"24h"` // Default 1 day
+	IdleWarningMessage       string        `split_words:"true" default:"Sandbox {{ .Sandbox.Name }} has been idle since {{ .LastActivity }} and will be deleted at {{ .IdleReapDate }}."`
+	IdleReapMessage          string        `split_words:"true" default:"Sandbox {{ .Sandbox.Name }} has been deleted as it was idle since {{ .LastActivity }}."`
+	MaxLifetime              time.Duration `envconfig:"SANDBOX_MAX_LIFETIME" default:"720h"` // Shared with the webhook, default 30 days
 }
 
 // Reaper will reap sandboxes from the cluster.
 type Reaper struct {
-	sandboxClient *versioned.Clientset
+	sandboxClient versioned.Interface
+	kubeClient    kubernetes.Interface
 	config        *Config
 	notifier      notification.Notifier
 }
 
 func NewReaper(ctx context.Context, config *Config, notifier notification.Notifier) (*Reaper, error) {
-	logger := log.Ctx(ctx)
-
 	cfg, err := rest.InClusterConfig()
 	if err != nil && err != rest.ErrNotInCluster {
 		return nil, err
@@ -51,15 +61,31 @@ func NewReaper(ctx context.Context, config *Config, notifier notification.Notifi
 		}
 	}
 
+	reaper, err := NewReaperForConfig(cfg, config, notifier)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().Msg("Connected to Kubernetes")
+
+	return reaper, nil
+}
+
+// NewReaperForConfig returns a Reaper that connects to Kubernetes with the given rest.Config
+func NewReaperForConfig(cfg *rest.Config, config *Config, notifier notification.Notifier) (*Reaper, error) {
 	client, err := versioned.NewForConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Info().Msg("Connected to Kubernetes")
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Reaper{
 		sandboxClient: client,
+		kubeClient:    kubeClient,
 		config:        config,
 		notifier:      notifier,
 	}, nil
@@ -76,74 +102,212 @@ func (r *Reaper) Run(ctx context.Context) error {
 		return err
 	}
 
-	for _, sb := range sandboxes.Items {
-		logger.Debug().Str("sandbox", sb.Name).Msg("Inspecting Sandbox")
+	// A failure for one Sandbox does not keep the other Sandboxes from being reaped
+	errs := []error{}
+	for i := range sandboxes.Items {
+		sb := &sandboxes.Items[i]
+		if _, err := r.reap(ctx, sb); err != nil {
+			logger.Error().Err(err).Str("sandbox", sb.Name).Msg("Error while reaping sandbox")
+			errs = append(errs, fmt.Errorf("sandbox %s: %w", sb.Name, err))
+		}
+	}
 
-		if r.isExpired(ctx, sb) {
-			logger.Info().Str("sandbox", sb.Name).Msg("Sandbox is expired.")
+	if len(errs) > 0 {
+		logger.Error().Int("sandboxes", len(sandboxes.Items)).Int("failed", len(errs)).Msg("Finished reaping run with errors")
+		return fmt.Errorf("reaping failed for %d of %d sandboxes: %w", len(errs), len(sandboxes.Items), utilerrors.NewAggregate(errs))
+	}
 
-			if err := r.sandboxClient.DevopsV1().Sandboxes().Delete(ctx, sb.Name, v1.DeleteOptions{}); err != nil {
-				return err
-			}
+	logger.Info().Int("sandboxes", len(sandboxes.Items)).Msg("Finished reaping run")
 
-			if err := r.notify(ctx, r.config.ReapMessage, sb); err != nil {
-				return err
-			}
+	return nil
+}
 
-		} else if r.isExpirationImminent(ctx, sb) {
-			if r.shouldNotify(ctx, sb) {
-				logger.Info().Str("sandbox", sb.Name).Msg("Warning about imminent expiration")
+// reap deletes the Sandbox if it is expired or idle, or warns its owner if that is imminent, and records its
+// expiration and idle state in its status. It returns whether the Sandbox was deleted.
+func (r *Reaper) reap(ctx context.Context, sb *devopsv1.Sandbox) (bool, error) {
+	logger := log.Ctx(ctx)
+	logger.Debug().Str("sandbox", sb.Name).Msg("Inspecting Sandbox")
 
-				if err := r.notify(ctx, r.config.ExpirationWarningMessage, sb); err != nil {
-					return err
-				}
+	// A Sandbox that is being torn down has already been reaped, or was deleted by its owner
+	if !sb.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
 
-				if err := r.updateLastNotificationDate(ctx, sb); err != nil {
-					return err
-				}
-			}
-		} else if r.isExpirationOverdue(ctx, sb) {
-			if r.shouldNotify(ctx, sb) {
-				logger.Info().Str("sandbox", sb.Name).Msg("Manual expiry sandbox is overdue, notifying user")
+	original := sb.Status.DeepCopy()
 
-				if err := r.notify(ctx, r.config.ExpirationOverdueMessage, sb); err != nil {
-					return err
-				}
+	action, err := r.planAction(ctx, sb)
+	if err != nil {
+		return false, err
+	}
 
-				if err := r.updateLastNotificationDate(ctx, sb); err != nil {
-					return err
-				}
-			}
+	if action != nil && action.Action == ActionDelete {
+		logger.Info().Str("sandbox", sb.Name).Str("reason", string(action.Reason)).Msg("Deleting Sandbox")
 
+		if err := r.sandboxClient.DevopsV1().Sandboxes().Delete(ctx, sb.Name, v1.DeleteOptions{}); err != nil {
+			return false, err
 		}
+
+		return true, r.notifier.Notify("", action.Message)
 	}
 
-	logger.Info().Msg("Finished reaping run")
+	if action != nil {
+		logger.Info().Str("sandbox", sb.Name).Str("reason", string(action.Reason)).Msg("Notifying owner of Sandbox")
 
-	return nil
+		// Owners that are warned about the expiration of their Sandbox can extend it from the notification
+		extender, canExtend := r.notifier.(notification.ExtendNotifier)
+		if canExtend && action.Reason != ReasonIdleReapImminent {
+			err = extender.NotifyWithExtend("", action.Message, sb.Name)
+		} else {
+			err = r.notifier.Notify("", action.Message)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, r.updateStatus(ctx, sb, original, action != nil)
 }
 
-// notify uses the Reaper.notifier to notify that the Sandbox is either reaped, or will be reaped.
-func (r *Reaper) notify(ctx context.Context, message string, sb devopsv1.Sandbox) error {
-	msg, err := r.constructMessage(ctx, message, sb)
-	if err != nil {
-		return err
+// planAction decides whether the Sandbox is deleted because it is expired or idle, or whether its owner is warned
+// about its imminent or overdue expiration, and renders the message for its owner. It returns nil if nothing is done
+// with the Sandbox. The last activity in the Sandbox is recorded in its status if idle sandboxes are reaped.
+func (r *Reaper) planAction(ctx context.Context, sb *devopsv1.Sandbox) (*Action, error) {
+	if r.isExpired(ctx, *sb) {
+		return r.newAction(ctx, *sb, ActionDelete, ReasonExpired, r.config.ReapMessage)
+	}
+
+	if r.config.IdleTimeout > 0 {
+		lastActivity, err := r.lastActivity(ctx, *sb)
+		if err != nil {
+			return nil, err
+		}
+		sb.Status.LastActivity = &v1.Time{Time: lastActivity}
+	}
+
+	if r.isIdle(ctx, *sb) {
+		return r.newAction(ctx, *sb, ActionDelete, ReasonIdle, r.config.IdleReapMessage)
+	}
+
+	// The expiration and idle warnings are checked separately, so that the idle warning is still sent when the owner
+	// cannot be warned about the expiration yet
+	if r.isExpirationImminent(ctx, *sb) && r.shouldNotify(ctx, *sb) {
+		return r.newAction(ctx, *sb, ActionWarn, ReasonExpirationImminent, r.config.ExpirationWarningMessage)
+	}
+
+	if r.isExpirationOverdue(ctx, *sb) && r.shouldNotify(ctx, *sb) {
+		return r.newAction(ctx, *sb, ActionOverdue, ReasonExpirationOverdue, r.config.ExpirationOverdueMessage)
 	}
 
-	return r.notifier.Notify("", msg)
+	if r.isIdleImminent(ctx, *sb) && r.shouldNotifyIdle(ctx, *sb) {
+		return r.newAction(ctx, *sb, ActionWarn, ReasonIdleReapImminent, r.config.IdleWarningMessage)
+	}
+
+	return nil, nil
 }
 
-// updateLastNotificationDate updates the Sandbox.Status.LastNotification field with the date of `now`.
-func (r *Reaper) updateLastNotificationDate(ctx context.Context, sb devopsv1.Sandbox) error {
-	sb.Status.LastNotification = &v1.Time{Time: clock.Ctx(ctx).Now()}
+// nextCheck returns the first moment after now at which the Sandbox has to be reaped, or its owner may have to be
+// warned, or the zero time if there is no such moment.
+func (r *Reaper) nextCheck(ctx context.Context, sb devopsv1.Sandbox) time.Time {
+	now := clock.Ctx(ctx).Now()
+	expDate := r.expirationDate(ctx, sb)
+
+	muted := isMuted(sb)
 
-	if _, err := r.sandboxClient.DevopsV1().Sandboxes().UpdateStatus(ctx, &sb, v1.UpdateOptions{}); err != nil {
+	candidates := []time.Time{}
+	if sb.Spec.ManualExpiry {
+		candidates = append(candidates, expDate)
+	} else {
+		// isExpired only holds once the expiration date has passed
+		candidates = append(candidates, expDate.Add(-r.firstWarning(sb)), expDate.Add(time.Nanosecond))
+	}
+
+	if sb.Status.LastNotification != nil && !muted {
+		candidates = append(candidates, sb.Status.LastNotification.Add(r.warningInterval(sb)))
+	}
+
+	if reapDate := r.idleReapDate(sb); reapDate != nil {
+		candidates = append(candidates, *reapDate)
+		if !muted {
+			candidates = append(candidates, reapDate.Add(-r.config.IdleWarning))
+		}
+	}
+
+	next := time.Time{}
+	for _, candidate := range candidates {
+		if candidate.After(now) && (next.IsZero() || candidate.Before(next)) {
+			next = candidate
+		}
+	}
+
+	return next
+}
+
+// updateStatus updates the Sandbox.Status.LastNotification field with the date of `now` if the owner was notified,
+// records the effective expiration date, the expiration and idle conditions, and writes the status if it changed from
+// the original status.
+func (r *Reaper) updateStatus(ctx context.Context, sb *devopsv1.Sandbox, original *devopsv1.SandboxStatus, notified bool) error {
+	if notified {
+		sb.Status.LastNotification = &v1.Time{Time: clock.Ctx(ctx).Now()}
+	}
+	r.setIdleStatus(ctx, sb)
+	r.setExpirationStatus(ctx, sb)
+
+	if equality.Semantic.DeepEqual(original, &sb.Status) {
+		return nil
+	}
+
+	if _, err := r.sandboxClient.DevopsV1().Sandboxes().UpdateStatus(ctx, sb, v1.UpdateOptions{}); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// setExpirationStatus sets the effective expiration date, the Expiring and Overdue conditions and the resulting phase
+// in the status of the Sandbox.
+func (r *Reaper) setExpirationStatus(ctx context.Context, sb *devopsv1.Sandbox) {
+	now := clock.Ctx(ctx).Now()
+
+	sb.Status.ExpirationDate = &v1.Time{Time: r.expirationDate(ctx, *sb)}
+
+	if r.isExpirationImminent(ctx, *sb) {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionExpiring, v1.ConditionTrue, "ExpirationImminent",
+			fmt.Sprintf("Sandbox expires at %s", sb.Status.ExpirationDate.Format(time.RFC3339)), now)
+	} else {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionExpiring, v1.ConditionFalse, "ExpirationNotImminent", "", now)
+	}
+
+	if r.isExpirationOverdue(ctx, *sb) {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionOverdue, v1.ConditionTrue, "ExpirationOverdue",
+			fmt.Sprintf("Sandbox has manual expiry and was due at %s", sb.Status.ExpirationDate.Format(time.RFC3339)), now)
+	} else {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionOverdue, v1.ConditionFalse, "ExpirationNotOverdue", "", now)
+	}
+
+	pkgsandbox.UpdatePhase(sb)
+}
+
+// setIdleStatus sets the Idle condition in the status of the Sandbox, or removes it if the Sandbox is not reaped when
+// it is idle.
+func (r *Reaper) setIdleStatus(ctx context.Context, sb *devopsv1.Sandbox) {
+	reapDate := r.idleReapDate(*sb)
+	if reapDate == nil {
+		pkgsandbox.RemoveCondition(sb, devopsv1.ConditionIdle)
+		return
+	}
+
+	now := clock.Ctx(ctx).Now()
+	if r.isIdleImminent(ctx, *sb) {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionIdle, v1.ConditionTrue, "IdleReapImminent",
+			fmt.Sprintf("Sandbox has been idle since %s and is reaped at %s",
+				sb.Status.LastActivity.Format(time.RFC3339), reapDate.Format(time.RFC3339)), now)
+	} else {
+		pkgsandbox.SetCondition(sb, devopsv1.ConditionIdle, v1.ConditionFalse, "Active", "", now)
+	}
+}
+
+// expirationDate returns the ExpirationDate of the Sandbox, which is defaulted by the admission webhook. Sandboxes
+// created without the webhook fall back to the default TTL.
 func (r *Reaper) expirationDate(ctx context.Context, sb devopsv1.Sandbox) time.Time {
 	if sb.Spec.ExpirationDate != nil {
 		return sb.Spec.ExpirationDate.Time
@@ -171,25 +335,176 @@ func (r *Reaper) isExpirationImminent(ctx context.Context, sb devopsv1.Sandbox)
 
 	expDate := r.expirationDate(ctx, sb)
 	now := clock.Ctx(ctx).Now()
-	warnDate := expDate.Add(-r.config.FirstExpirationWarning)
+	warnDate := expDate.Add(-r.firstWarning(sb))
 
 	return now.After(warnDate) || now.Equal(warnDate)
 }
 
 // shouldNotify checks whether the Sandbox owner should be notified about a pending expiry
 func (r *Reaper) shouldNotify(ctx context.Context, sb devopsv1.Sandbox) bool {
+	if isMuted(sb) {
+		return false
+	}
+
 	expDate := r.expirationDate(ctx, sb)
 	now := clock.Ctx(ctx).Now()
 
-	notification := expDate.Add(-r.config.FirstExpirationWarning)
+	notification := expDate.Add(-r.firstWarning(sb))
 	if sb.Status.LastNotification != nil {
-		notification = sb.Status.LastNotification.Add(r.config.WarningInterval)
+		notification = sb.Status.LastNotification.Add(r.warningInterval(sb))
 	}
 
 	return now.After(notification) || now.Equal(notification)
 
 }
 
+// firstWarning returns how long before its expiration date the owner of the Sandbox is first warned
+func (r *Reaper) firstWarning(sb devopsv1.Sandbox) time.Duration {
+	if sb.Spec.Warnings != nil && sb.Spec.Warnings.FirstWarning != nil {
+		return sb.Spec.Warnings.FirstWarning.Duration
+	}
+
+	return r.config.FirstExpirationWarning
+}
+
+// warningInterval returns the time between consecutive warnings to the owner of the Sandbox
+func (r *Reaper) warningInterval(sb devopsv1.Sandbox) time.Duration {
+	if sb.Spec.Warnings != nil && sb.Spec.Warnings.Interval != nil {
+		return sb.Spec.Warnings.Interval.Duration
+	}
+
+	return r.config.WarningInterval
+}
+
+// isMuted checks whether the owner of the Sandbox has opted out of warnings
+func isMuted(sb devopsv1.Sandbox) bool {
+	return sb.Spec.Warnings != nil && sb.Spec.Warnings.Mute
+}
+
+// lastActivity returns the last time a pod was created or restarted, or a Deployment or StatefulSet was rolled out in
+// the namespaces of the Sandbox. It is never before the creation of the Sandbox or the activity that was recorded
+// before, as the evidence of older activity disappears when pods are removed.
+//
+// Every pod counts, so the pods of CronJobs and the restarts of crash looping containers keep a Sandbox active too.
+// The idle clock is stopped while the Sandbox is hibernated, on request or outside of its uptime window: the recorded
+// activity is kept as it is, and the idle time is measured again from the moment the Sandbox is restored.
+func (r *Reaper) lastActivity(ctx context.Context, sb devopsv1.Sandbox) (time.Time, error) {
+	last := sb.CreationTimestamp.Time
+	observe := func(t time.Time) {
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	if sb.Status.LastActivity != nil {
+		observe(sb.Status.LastActivity.Time)
+	}
+
+	if hibernated := meta.FindStatusCondition(sb.Status.Conditions, devopsv1.ConditionHibernated); hibernated != nil {
+		if hibernated.Status == v1.ConditionTrue {
+			return last, nil
+		}
+		observe(hibernated.LastTransitionTime.Time)
+	}
+
+	for _, ns := range pkgsandbox.NamespaceNames(&sb) {
+		pods, err := r.kubeClient.CoreV1().Pods(ns).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		for _, pod := range pods.Items {
+			observe(pod.CreationTimestamp.Time)
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				if status.RestartCount == 0 {
+					continue
+				}
+				if status.State.Running != nil {
+					observe(status.State.Running.StartedAt.Time)
+				}
+				if status.LastTerminationState.Terminated != nil {
+					observe(status.LastTerminationState.Terminated.FinishedAt.Time)
+				}
+			}
+		}
+
+		// Every rollout of a Deployment creates or scales a ReplicaSet, and every rollout of a StatefulSet creates a
+		// ControllerRevision
+		replicaSets, err := r.kubeClient.AppsV1().ReplicaSets(ns).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		for _, rs := range replicaSets.Items {
+			observe(rs.CreationTimestamp.Time)
+		}
+
+		revisions, err := r.kubeClient.AppsV1().ControllerRevisions(ns).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		for _, revision := range revisions.Items {
+			observe(revision.CreationTimestamp.Time)
+		}
+	}
+
+	return last, nil
+}
+
+// idleReapDate returns the date on which the Sandbox is reaped if there is no activity in it until then, or nil if
+// the Sandbox is not reaped when it is idle. A hibernated Sandbox is not reaped, as its idle clock is stopped.
+func (r *Reaper) idleReapDate(sb devopsv1.Sandbox) *time.Time {
+	if r.config.IdleTimeout == 0 || sb.Spec.ManualExpiry || sb.Spec.DisableIdleReaping || sb.Status.LastActivity == nil {
+		return nil
+	}
+
+	if meta.IsStatusConditionTrue(sb.Status.Conditions, devopsv1.ConditionHibernated) {
+		return nil
+	}
+
+	reapDate := sb.Status.LastActivity.Add(r.config.IdleTimeout)
+	return &reapDate
+}
+
+// isIdle checks whether the Sandbox has had no activity for longer than the idle timeout
+func (r *Reaper) isIdle(ctx context.Context, sb devopsv1.Sandbox) bool {
+	reapDate := r.idleReapDate(sb)
+	if reapDate == nil {
+		return false
+	}
+
+	return !clock.Ctx(ctx).Now().Before(*reapDate)
+}
+
+// isIdleImminent checks whether the Sandbox will soon be reaped because it is idle
+func (r *Reaper) isIdleImminent(ctx context.Context, sb devopsv1.Sandbox) bool {
+	reapDate := r.idleReapDate(sb)
+	if reapDate == nil {
+		return false
+	}
+
+	return !clock.Ctx(ctx).Now().Before(reapDate.Add(-r.config.IdleWarning))
+}
+
+// shouldNotifyIdle checks whether the Sandbox owner should be notified about a pending reap of an idle Sandbox. The
+// first warning is sent IdleWarning before the reap, but never within the warning interval of a previous notification.
+func (r *Reaper) shouldNotifyIdle(ctx context.Context, sb devopsv1.Sandbox) bool {
+	reapDate := r.idleReapDate(sb)
+	if reapDate == nil || isMuted(sb) {
+		return false
+	}
+
+	notification := reapDate.Add(-r.config.IdleWarning)
+	if sb.Status.LastNotification != nil {
+		if next := sb.Status.LastNotification.Add(r.warningInterval(sb)); next.After(notification) {
+			notification = next
+		}
+	}
+
+	return !clock.Ctx(ctx).Now().Before(notification)
+}
+
 // isExpirationOverdue checks whether a Sandbox that has Sandbox.Spec.KeepAlive set has passed its expiry.
 func (r *Reaper) isExpirationOverdue(ctx context.Context, sb devopsv1.Sandbox) bool {
 	if !sb.Spec.ManualExpiry {
@@ -208,6 +523,12 @@ func (r *Reaper) constructMessage(ctx context.Context, message string, sb devops
 		"Sandbox":        sb,
 		"ExpirationDate": r.expirationDate(ctx, sb),
 	}
+	if sb.Status.LastActivity != nil {
+		m["LastActivity"] = sb.Status.LastActivity.Time
+	}
+	if reapDate := r.idleReapDate(sb); reapDate != nil {
+		m["IdleReapDate"] = *reapDate
+	}
 
 	t, err := template.New("notification").Parse(message)
 	if err != nil {
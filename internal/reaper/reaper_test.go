@@ -8,8 +8,14 @@ import (
 	clk "github.com/benbjohnson/clock"
 	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
 	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
 	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 var Day = 24 * time.Hour
@@ -117,6 +123,43 @@ func TestExpirationNotification(t *testing.T) {
 	}
 }
 
+func TestWarningPolicy(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+	var tests = map[string]struct {
+		warnings     *devopsv1.WarningPolicy
+		createdAgo   time.Duration
+		notification *time.Duration
+		isImminent   bool
+		isNotify     bool
+	}{
+		"No policy: Notification if hit global first notify point": {nil, -3 * Day, nil, true, true},
+		"Later first warning: No notification":                     {&devopsv1.WarningPolicy{FirstWarning: &v1.Duration{Duration: 1 * Day}}, -3 * Day, nil, false, false},
+		"Earlier first warning: Notification":                      {&devopsv1.WarningPolicy{FirstWarning: &v1.Duration{Duration: 6 * Day}}, -2 * Day, nil, true, true},
+		"Shorter interval: Notification":                           {&devopsv1.WarningPolicy{Interval: &v1.Duration{Duration: 12 * time.Hour}}, -5 * Day, pDuration(-13 * time.Hour), true, true},
+		"Longer interval: No notification":                         {&devopsv1.WarningPolicy{Interval: &v1.Duration{Duration: 3 * Day}}, -5 * Day, pDuration(-2 * Day), true, false},
+		"Muted: No notification":                                   {&devopsv1.WarningPolicy{Mute: true}, -3 * Day, nil, true, false},
+	}
+
+	reaper := &Reaper{
+		config: &Config{
+			DefaultTtl:             7 * Day,
+			FirstExpirationWarning: 4 * Day,
+			WarningInterval:        2 * Day,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := newSandbox(c, data.createdAgo, nil, false)
+			sandbox.Spec.Warnings = data.warnings
+			sandbox.Status.LastNotification = newTime(pTime(c, data.notification))
+			assert.Equal(t, reaper.isExpirationImminent(ctx, sandbox), data.isImminent)
+			assert.Equal(t, reaper.shouldNotify(ctx, sandbox), data.isNotify)
+		})
+	}
+}
+
 func TestConstructMessage(t *testing.T) {
 	reaper := &Reaper{
 		config: &Config{
@@ -169,3 +212,222 @@ func newTime(t *time.Time) *v1.Time {
 		Time: *t,
 	}
 }
+
+func TestExpirationStatus(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+	var tests = map[string]struct {
+		createdAgo time.Duration
+		expiration *time.Duration
+		keepAlive  bool
+		isExpiring bool
+		isOverdue  bool
+		phase      devopsv1.SandboxPhase
+	}{
+		"Ready if expiration not imminent":   {-1 * Day, nil, false, false, false, devopsv1.SandboxReady},
+		"Expiring if expiration imminent":    {-4 * Day, nil, false, true, false, devopsv1.SandboxExpiring},
+		"Overdue if keep alive and past due": {-8 * Day, nil, true, false, true, devopsv1.SandboxOverdue},
+		"Expiring by expiration date":        {-1 * Day, pDuration(1 * Day), false, true, false, devopsv1.SandboxExpiring},
+	}
+
+	reaper := &Reaper{
+		config: &Config{
+			DefaultTtl:             7 * Day,
+			FirstExpirationWarning: 4 * Day,
+			WarningInterval:        2 * Day,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := newSandbox(c, data.createdAgo, data.expiration, data.keepAlive)
+			pkgsandbox.SetCondition(&sandbox, devopsv1.ConditionNamespaceReady, v1.ConditionTrue, "NamespaceActive", "", c.Now())
+			pkgsandbox.SetCondition(&sandbox, devopsv1.ConditionRBACReady, v1.ConditionTrue, "RoleBindingReady", "", c.Now())
+
+			reaper.setExpirationStatus(ctx, &sandbox)
+			assert.Equal(t, sandbox.Status.ExpirationDate.Time, reaper.expirationDate(ctx, sandbox))
+			assert.Equal(t, meta.IsStatusConditionTrue(sandbox.Status.Conditions, devopsv1.ConditionExpiring), data.isExpiring)
+			assert.Equal(t, meta.IsStatusConditionTrue(sandbox.Status.Conditions, devopsv1.ConditionOverdue), data.isOverdue)
+			assert.Equal(t, sandbox.Status.Phase, data.phase)
+		})
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+	namespace := "sandbox-jdoe-test-1"
+
+	restarted := corev1.ContainerStatus{
+		RestartCount: 1,
+		State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: *newTime(pTime(c, pDuration(-2*Day)))}},
+	}
+
+	var tests = map[string]struct {
+		recorded *time.Duration
+		objects  []runtime.Object
+		expected time.Duration
+	}{
+		"Creation without activity": {nil, nil, -10 * Day},
+		"Recorded activity":         {pDuration(-5 * Day), nil, -5 * Day},
+		"Pod created": {pDuration(-5 * Day), []runtime.Object{
+			&corev1.Pod{ObjectMeta: v1.ObjectMeta{Namespace: namespace, Name: "web", CreationTimestamp: *newTime(pTime(c, pDuration(-3*Day)))}},
+		}, -3 * Day},
+		"Container restarted": {nil, []runtime.Object{
+			&corev1.Pod{
+				ObjectMeta: v1.ObjectMeta{Namespace: namespace, Name: "web", CreationTimestamp: *newTime(pTime(c, pDuration(-9*Day)))},
+				Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{restarted}},
+			},
+		}, -2 * Day},
+		"Deployment rolled out": {nil, []runtime.Object{
+			&appsv1.ReplicaSet{ObjectMeta: v1.ObjectMeta{Namespace: namespace, Name: "web-1", CreationTimestamp: *newTime(pTime(c, pDuration(-1*Day)))}},
+		}, -1 * Day},
+		"Activity in other namespace": {nil, []runtime.Object{
+			&corev1.Pod{ObjectMeta: v1.ObjectMeta{Namespace: "default", Name: "web", CreationTimestamp: *newTime(pTime(c, pDuration(-1*Day)))}},
+		}, -10 * Day},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			reaper := &Reaper{
+				kubeClient: fake.NewSimpleClientset(data.objects...),
+				config:     &Config{},
+			}
+
+			sandbox := newSandbox(c, -10*Day, nil, false)
+			sandbox.Name = "test-1"
+			sandbox.Spec.User = "jdoe"
+			sandbox.Status.LastActivity = newTime(pTime(c, data.recorded))
+
+			lastActivity, err := reaper.lastActivity(ctx, sandbox)
+			assert.NilError(t, err)
+			assert.Assert(t, lastActivity.Equal(c.Now().Add(data.expected)), lastActivity)
+		})
+	}
+}
+
+func TestLastActivityWhileHibernated(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	var tests = map[string]struct {
+		status     v1.ConditionStatus
+		transition time.Duration
+		expected   time.Duration
+	}{
+		"Hibernated":                 {v1.ConditionTrue, -3 * Day, -5 * Day},
+		"Restored after hibernation": {v1.ConditionFalse, -2 * Day, -2 * Day},
+		"Restored before activity":   {v1.ConditionFalse, -8 * Day, -5 * Day},
+	}
+
+	reaper := &Reaper{
+		kubeClient: fake.NewSimpleClientset(),
+		config:     &Config{},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := newSandbox(c, -10*Day, nil, false)
+			sandbox.Status.LastActivity = newTime(pTime(c, pDuration(-5*Day)))
+			sandbox.Status.Conditions = []v1.Condition{{
+				Type:               devopsv1.ConditionHibernated,
+				Status:             data.status,
+				LastTransitionTime: *newTime(pTime(c, &data.transition)),
+			}}
+
+			lastActivity, err := reaper.lastActivity(ctx, sandbox)
+			assert.NilError(t, err)
+			assert.Assert(t, lastActivity.Equal(c.Now().Add(data.expected)), lastActivity)
+		})
+	}
+}
+
+func TestIdle(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+	var tests = map[string]struct {
+		lastActivity time.Duration
+		notification *time.Duration
+		optOut       bool
+		hibernated   bool
+		isIdle       bool
+		isImminent   bool
+		isNotify     bool
+	}{
+		"Active":                                 {-1 * Day, nil, false, false, false, false, false},
+		"Idle reap imminent":                     {-6 * Day, nil, false, false, false, true, true},
+		"Idle reap imminent and recently warned": {-6 * Day, pDuration(-1 * time.Hour), false, false, false, true, false},
+		"Idle":                                   {-8 * Day, nil, false, false, true, true, true},
+		"Idle but opted out":                     {-8 * Day, nil, true, false, false, false, false},
+		"Idle but hibernated":                    {-8 * Day, nil, false, true, false, false, false},
+	}
+
+	reaper := &Reaper{
+		config: &Config{
+			IdleTimeout:     7 * Day,
+			IdleWarning:     2 * Day,
+			WarningInterval: 1 * Day,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := newSandbox(c, -10*Day, nil, false)
+			sandbox.Spec.DisableIdleReaping = data.optOut
+			sandbox.Status.LastActivity = newTime(pTime(c, &data.lastActivity))
+			sandbox.Status.LastNotification = newTime(pTime(c, data.notification))
+			if data.hibernated {
+				pkgsandbox.SetCondition(&sandbox, devopsv1.ConditionHibernated, v1.ConditionTrue, "ScaledDown", "", c.Now().Add(-3*Day))
+			}
+
+			assert.Equal(t, reaper.isIdle(ctx, sandbox), data.isIdle)
+			assert.Equal(t, reaper.isIdleImminent(ctx, sandbox), data.isImminent)
+			assert.Equal(t, reaper.shouldNotifyIdle(ctx, sandbox), data.isNotify)
+		})
+	}
+}
+
+func TestNextCheck(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+	var tests = map[string]struct {
+		expiration   time.Duration
+		notification *time.Duration
+		keepAlive    bool
+		lastActivity *time.Duration
+		warnings     *devopsv1.WarningPolicy
+		expected     time.Duration
+	}{
+		"Before the first warning":             {5 * Day, nil, false, nil, nil, 2 * Day},
+		"Warned about imminent expiration":     {2 * Day, pDuration(-1 * time.Hour), false, nil, nil, 23 * time.Hour},
+		"Expiration before the next warning":   {time.Hour, pDuration(-1 * time.Hour), false, nil, nil, time.Hour + time.Nanosecond},
+		"Manual expiry before its due date":    {4 * Day, nil, true, nil, nil, 4 * Day},
+		"Manual expiry overdue":                {-1 * Day, pDuration(-2 * time.Hour), true, nil, nil, 22 * time.Hour},
+		"Idle reap before the first warning":   {30 * Day, nil, false, pDuration(-1 * Day), nil, 4 * Day},
+		"Idle reap after the warning was sent": {30 * Day, pDuration(-1 * time.Hour), false, pDuration(-6 * Day), nil, 23 * time.Hour},
+		"Muted before expiration":              {2 * Day, pDuration(-1 * time.Hour), false, nil, &devopsv1.WarningPolicy{Mute: true}, 2*Day + time.Nanosecond},
+		"Muted before the idle reap":           {30 * Day, pDuration(-1 * time.Hour), false, pDuration(-6 * Day), &devopsv1.WarningPolicy{Mute: true}, 1 * Day},
+		"Custom first warning":                 {5 * Day, nil, false, nil, &devopsv1.WarningPolicy{FirstWarning: &v1.Duration{Duration: 1 * Day}}, 4 * Day},
+		"Custom warning interval":              {2 * Day, pDuration(-1 * time.Hour), false, nil, &devopsv1.WarningPolicy{Interval: &v1.Duration{Duration: 6 * time.Hour}}, 5 * time.Hour},
+	}
+
+	reaper := &Reaper{
+		config: &Config{
+			FirstExpirationWarning: 3 * Day,
+			WarningInterval:        1 * Day,
+			IdleTimeout:            7 * Day,
+			IdleWarning:            2 * Day,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := newSandbox(c, -1*Day, &data.expiration, data.keepAlive)
+			sandbox.Status.LastNotification = newTime(pTime(c, data.notification))
+			sandbox.Status.LastActivity = newTime(pTime(c, data.lastActivity))
+			sandbox.Spec.Warnings = data.warnings
+
+			assert.Equal(t, reaper.nextCheck(ctx, sandbox).Sub(c.Now()), data.expected)
+		})
+	}
+}
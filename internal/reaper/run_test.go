@@ -0,0 +1,86 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"github.com/stackvista/sandbox-operator/internal/notification"
+	"github.com/stackvista/sandbox-operator/pkg/client/versioned/fake"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRunContinuesAfterErrors(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	client := fake.NewSimpleClientset()
+	for name, expiration := range map[string]*time.Duration{
+		"bad-delete": pDuration(-1 * Day),
+		"bad-slack":  pDuration(1 * Day),
+		"expired":    pDuration(-1 * Day),
+		"expiring":   pDuration(1 * Day),
+	} {
+		sb := newSandbox(c, -10*Day, expiration, false)
+		sb.Name = name
+		_, err := client.DevopsV1().Sandboxes().Create(ctx, &sb, v1.CreateOptions{})
+		assert.NilError(t, err)
+	}
+
+	client.PrependReactor("delete", "sandboxes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() == "bad-delete" {
+			return true, nil, fmt.Errorf("the server is on fire")
+		}
+		return false, nil, nil
+	})
+
+	notifier := notification.NewMock()
+	notifier.Err = func(message string) error {
+		if strings.Contains(message, "bad-slack") {
+			return fmt.Errorf("slack is unavailable")
+		}
+		return nil
+	}
+
+	reaper := &Reaper{
+		sandboxClient: client,
+		config: &Config{
+			FirstExpirationWarning:   3 * Day,
+			WarningInterval:          1 * Day,
+			ExpirationWarningMessage: "Sandbox {{ .Sandbox.Name }} expires soon",
+			ReapMessage:              "Sandbox {{ .Sandbox.Name }} is deleted",
+		},
+		notifier: notifier,
+	}
+
+	err := reaper.Run(ctx)
+	assert.ErrorContains(t, err, "reaping failed for 2 of 4 sandboxes")
+	assert.ErrorContains(t, err, "sandbox bad-delete: the server is on fire")
+	assert.ErrorContains(t, err, "sandbox bad-slack: slack is unavailable")
+
+	// The other sandboxes are reaped and warned about as usual
+	assert.Equal(t, len(notifier.Notifications), 2)
+
+	_, err = client.DevopsV1().Sandboxes().Get(ctx, "expired", v1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err))
+
+	expiring, err := client.DevopsV1().Sandboxes().Get(ctx, "expiring", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, expiring.Status.LastNotification != nil)
+
+	// The failed warning is not recorded, so that it is retried in the next run
+	badSlack, err := client.DevopsV1().Sandboxes().Get(ctx, "bad-slack", v1.GetOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, badSlack.Status.LastNotification == nil)
+
+	_, err = client.DevopsV1().Sandboxes().Get(ctx, "bad-delete", v1.GetOptions{})
+	assert.NilError(t, err)
+}
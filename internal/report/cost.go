@@ -0,0 +1,182 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// HoursPerMonth is the average number of hours in a month, used to extrapolate the monthly cost
+	HoursPerMonth = 730
+
+	// NoTeam groups the sandboxes that do not have a team label
+	NoTeam = "(none)"
+)
+
+// Totals are the summed resource requests and cost of a group of sandboxes
+type Totals struct {
+	Name        string            `json:"name"`
+	Sandboxes   int               `json:"sandboxes"`
+	CPU         resource.Quantity `json:"cpu"`
+	Memory      resource.Quantity `json:"memory"`
+	Storage     resource.Quantity `json:"storage"`
+	HourlyCost  float64           `json:"hourlyCost"`
+	MonthlyCost float64           `json:"monthlyCost"`
+}
+
+// CostReport reports the cost of the sandboxes per user and per team
+type CostReport struct {
+	Currency string    `json:"currency"`
+	Users    []*Totals `json:"users"`
+	Teams    []*Totals `json:"teams"`
+}
+
+// Cost totals the usage that the operator recorded in the status of the sandboxes per user, and per team as given by
+// the teamLabel on the sandboxes.
+func Cost(sandboxes []devopsv1.Sandbox, teamLabel string) (*CostReport, error) {
+	users := map[string]*Totals{}
+	teams := map[string]*Totals{}
+	currency := ""
+
+	for i := range sandboxes {
+		sb := &sandboxes[i]
+		team := sb.Labels[teamLabel]
+		if team == "" {
+			team = NoTeam
+		}
+
+		hourlyCost := 0.0
+		if usage := sb.Status.Usage; usage != nil && usage.HourlyCost != "" {
+			if currency != "" && usage.Currency != currency {
+				return nil, fmt.Errorf("sandbox %s is priced in %s instead of %s", sb.Name, usage.Currency, currency)
+			}
+			currency = usage.Currency
+
+			var err error
+			if hourlyCost, err = strconv.ParseFloat(usage.HourlyCost, 64); err != nil {
+				return nil, fmt.Errorf("sandbox %s has an invalid hourly cost %q: %w", sb.Name, usage.HourlyCost, err)
+			}
+		}
+
+		for _, totals := range []*Totals{totalsFor(users, sb.Spec.User), totalsFor(teams, team)} {
+			totals.add(sb.Status.Usage, hourlyCost)
+		}
+	}
+
+	return &CostReport{
+		Currency: currency,
+		Users:    sorted(users),
+		Teams:    sorted(teams),
+	}, nil
+}
+
+func totalsFor(groups map[string]*Totals, name string) *Totals {
+	if _, ok := groups[name]; !ok {
+		groups[name] = &Totals{Name: name}
+	}
+
+	return groups[name]
+}
+
+func (t *Totals) add(usage *devopsv1.SandboxUsage, hourlyCost float64) {
+	t.Sandboxes++
+	t.HourlyCost += hourlyCost
+	t.MonthlyCost = t.HourlyCost * HoursPerMonth
+
+	if usage == nil {
+		return
+	}
+
+	t.CPU.Add(usage.Requests[corev1.ResourceCPU])
+	t.Memory.Add(usage.Requests[corev1.ResourceMemory])
+	t.Storage.Add(usage.Requests[corev1.ResourceStorage])
+}
+
+// sorted returns the totals ordered by descending cost, and by name for equal costs
+func sorted(groups map[string]*Totals) []*Totals {
+	list := []*Totals{}
+	for _, totals := range groups {
+		list = append(list, totals)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].HourlyCost != list[j].HourlyCost {
+			return list[i].HourlyCost > list[j].HourlyCost
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// Write writes the report to w as a table, csv or json
+func (r *CostReport) Write(w io.Writer, format string) error {
+	switch format {
+	case "table", "":
+		return r.writeTable(w)
+	case "csv":
+		return r.writeCSV(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, csv or json", format)
+	}
+}
+
+func (r *CostReport) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	for i, section := range []struct {
+		header string
+		rows   []*Totals
+	}{{"USER", r.Users}, {"TEAM", r.Teams}} {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		fmt.Fprintf(tw, "%s\tSANDBOXES\tCPU\tMEMORY\tSTORAGE\tHOURLY (%s)\tMONTHLY (%s)\n", section.header, r.Currency, r.Currency)
+		for _, t := range section.rows {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", t.Name, t.Sandboxes, t.CPU.String(), t.Memory.String(),
+				t.Storage.String(), formatCost(t.HourlyCost), formatCost(t.MonthlyCost))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (r *CostReport) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"group", "name", "sandboxes", "cpu", "memory", "storage", "hourly_cost", "monthly_cost", "currency"}); err != nil {
+		return err
+	}
+
+	for _, section := range []struct {
+		group string
+		rows  []*Totals
+	}{{"user", r.Users}, {"team", r.Teams}} {
+		for _, t := range section.rows {
+			record := []string{section.group, t.Name, strconv.Itoa(t.Sandboxes), t.CPU.String(), t.Memory.String(),
+				t.Storage.String(), formatCost(t.HourlyCost), formatCost(t.MonthlyCost), r.Currency}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 2, 64)
+}
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCost(t *testing.T) {
+	sandboxes := []devopsv1.Sandbox{
+		sandbox("test-1", "jdoe", "platform", "2", "4Gi", "0.0760"),
+		sandbox("test-2", "jdoe", "platform", "1", "2Gi", "0.0380"),
+		sandbox("test-3", "asmith", "", "4", "8Gi", "0.1520"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "test-4"}, Spec: devopsv1.SandboxSpec{User: "asmith"}},
+	}
+
+	report, err := Cost(sandboxes, "team")
+	assert.NilError(t, err)
+
+	out := &bytes.Buffer{}
+	assert.NilError(t, report.Write(out, "table"))
+	assert.Equal(t, out.String(), ""+
+		"USER     SANDBOXES   CPU   MEMORY   STORAGE   HOURLY (USD)   MONTHLY (USD)\n"+
+		"asmith   2           4     8Gi      0         0.15           110.96\n"+
+		"jdoe     2           3     6Gi      0         0.11           83.22\n"+
+		"\n"+
+		"TEAM       SANDBOXES   CPU   MEMORY   STORAGE   HOURLY (USD)   MONTHLY (USD)\n"+
+		"(none)     2           4     8Gi      0         0.15           110.96\n"+
+		"platform   2           3     6Gi      0         0.11           83.22\n")
+
+	out.Reset()
+	assert.NilError(t, report.Write(out, "csv"))
+	assert.Equal(t, out.String(), ""+
+		"group,name,sandboxes,cpu,memory,storage,hourly_cost,monthly_cost,currency\n"+
+		"user,asmith,2,4,8Gi,0,0.15,110.96,USD\n"+
+		"user,jdoe,2,3,6Gi,0,0.11,83.22,USD\n"+
+		"team,(none),2,4,8Gi,0,0.15,110.96,USD\n"+
+		"team,platform,2,3,6Gi,0,0.11,83.22,USD\n")
+
+	assert.ErrorContains(t, report.Write(out, "yaml"), "unknown output format")
+}
+
+func TestCostMixedCurrencies(t *testing.T) {
+	euros := sandbox("test-2", "jdoe", "", "1", "1Gi", "0.03")
+	euros.Status.Usage.Currency = "EUR"
+
+	_, err := Cost([]devopsv1.Sandbox{sandbox("test-1", "jdoe", "", "1", "1Gi", "0.03"), euros}, "team")
+	assert.ErrorContains(t, err, "sandbox test-2 is priced in EUR instead of USD")
+}
+
+func sandbox(name, user, team, cpu, memory, hourlyCost string) devopsv1.Sandbox {
+	sb := devopsv1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       devopsv1.SandboxSpec{User: user},
+		Status: devopsv1.SandboxStatus{Usage: &devopsv1.SandboxUsage{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			HourlyCost: hourlyCost,
+			Currency:   "USD",
+		}},
+	}
+	if team != "" {
+		sb.Labels = map[string]string{"team": team}
+	}
+
+	return sb
+}
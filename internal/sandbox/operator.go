@@ -2,20 +2,32 @@ package sandbox
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/butonic/zerologr"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	devopsv2 "github.com/stackvista/sandbox-operator/apis/devops/v2"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	devopscontroller "github.com/stackvista/sandbox-operator/controllers/devops"
+	"github.com/stackvista/sandbox-operator/internal/notification"
+	"github.com/stackvista/sandbox-operator/internal/notification/slack"
+	"github.com/stackvista/sandbox-operator/internal/reaper"
+	sandboxwebhook "github.com/stackvista/sandbox-operator/internal/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -28,12 +40,25 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(devopsv1.AddToScheme(scheme))
+	utilruntime.Must(devopsv2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 type OperatorConfig struct {
 	MetricsAddr          string
 	EnableLeaderElection bool
+	EnableWebhooks       bool
+	EnableReaper         bool
+	Controller           devopscontroller.Config
+	Webhook              sandboxwebhook.Config
+	SlackIdLookup        sandboxwebhook.SlackIdLookup
+	Reaper               reaper.Config
+	// Notifier notifies the owners of sandboxes that are reaped, it is required when EnableReaper is set
+	Notifier notification.Notifier
+	// SlackInteractionsAddr is the address on which the buttons in Slack notifications are handled, if it is set
+	SlackInteractionsAddr string
+	// Slacker verifies the Slack interactions, it is required when SlackInteractionsAddr is set
+	Slacker *slack.Slacker
 }
 
 func StartOperator(ctx context.Context, config *OperatorConfig) error {
@@ -42,6 +67,11 @@ func StartOperator(ctx context.Context, config *OperatorConfig) error {
 		Logger: logger,
 	}))
 
+	// Without a signing secret anyone could extend sandboxes through the interactions endpoint
+	if config.SlackInteractionsAddr != "" && (config.Slacker == nil || !config.Slacker.VerifiesInteractions()) {
+		return fmt.Errorf("serving the Slack interactions on %s requires SLACK_SIGNING_SECRET to be set", config.SlackInteractionsAddr)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: config.MetricsAddr,
@@ -49,6 +79,8 @@ func StartOperator(ctx context.Context, config *OperatorConfig) error {
 		LeaderElection:     config.EnableLeaderElection,
 		LeaderElectionID:   "6221cfa4.devopserator.stackstate.com",
 		Namespace:          "",
+		// Secrets are read directly, so that not every Secret of the cluster is cached
+		ClientDisableCacheFor: []client.Object{&corev1.Secret{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -57,12 +89,62 @@ func StartOperator(ctx context.Context, config *OperatorConfig) error {
 
 	if err = (&devopscontroller.SandboxReconciler{
 		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
 		Log:    ctrl.Log.WithName("controllers").WithName("Sandbox"),
 		Scheme: mgr.GetScheme(),
+		Config: &config.Controller,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Sandbox")
 		os.Exit(1)
 	}
+
+	if err = (&devopscontroller.SandboxUsageReconciler{
+		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
+		Log:    ctrl.Log.WithName("controllers").WithName("SandboxUsage"),
+		Config: &config.Controller,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SandboxUsage")
+		os.Exit(1)
+	}
+
+	if config.EnableReaper || config.SlackInteractionsAddr != "" {
+		r, err := reaper.NewReaperForConfig(mgr.GetConfig(), &config.Reaper, config.Notifier)
+		if err != nil {
+			setupLog.Error(err, "unable to create reaper")
+			os.Exit(1)
+		}
+
+		if config.EnableReaper {
+			if err = (&reaper.Controller{Reaper: r, Logger: logger}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "Reaper")
+				os.Exit(1)
+			}
+		}
+
+		if config.SlackInteractionsAddr != "" {
+			server := &httpServer{addr: config.SlackInteractionsAddr, handler: config.Slacker.InteractionHandler(r), logger: logger}
+			if err = mgr.Add(server); err != nil {
+				setupLog.Error(err, "unable to serve Slack interactions")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if config.EnableWebhooks {
+		// Registers the conversion webhook between the Sandbox API versions
+		if err = ctrl.NewWebhookManagedBy(mgr).For(&devopsv1.Sandbox{}).Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Sandbox")
+			os.Exit(1)
+		}
+
+		mgr.GetWebhookServer().Register(sandboxwebhook.DefaultPath, &webhook.Admission{
+			Handler: &sandboxwebhook.SandboxDefaulter{Config: &config.Webhook, Lookup: config.SlackIdLookup},
+		})
+		mgr.GetWebhookServer().Register(sandboxwebhook.ValidatePath, &webhook.Admission{
+			Handler: &sandboxwebhook.SandboxValidator{Config: &config.Webhook, Client: mgr.GetClient()},
+		})
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -73,3 +155,53 @@ func StartOperator(ctx context.Context, config *OperatorConfig) error {
 
 	return nil
 }
+
+// httpServer serves a handler on every replica of the operator, as it does not need leader election
+type httpServer struct {
+	addr    string
+	handler http.Handler
+	logger  *zerolog.Logger
+}
+
+// shutdowner is implemented by handlers that keep working on requests after they are answered, the server waits for
+// them before it stops
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Start implements manager.Runnable
+func (s *httpServer) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:    s.addr,
+		Handler: s.handler,
+		// Requests are handled with the logger of the operator
+		BaseContext: func(net.Listener) context.Context { return s.logger.WithContext(ctx) },
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+
+	// ListenAndServe returns as soon as the shutdown starts, the requests in flight are finished afterwards
+	<-stopped
+	if handler, ok := s.handler.(shutdowner); ok {
+		s.logger.Info().Str("addr", s.addr).Msg("Waiting for the handling of requests to finish")
+		if err := handler.Shutdown(context.Background()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *httpServer) NeedLeaderElection() bool {
+	return false
+}
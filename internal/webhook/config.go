@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"time"
+
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+)
+
+// Config holds the operator-wide settings of the admission webhooks.
+type Config struct {
+	MaxLifetime time.Duration     `split_words:"true" default:"720h"`      // Default 30 days
+	DefaultTtl  time.Duration     `envconfig:"DEFAULT_TTL" default:"168h"` // Shared with the reaper, default 1 week
+	SlackIds    map[string]string `split_words:"true"`                     // Slack IDs of users, as user:id pairs
+
+	SlackLookupTimeout time.Duration `split_words:"true" default:"2s"` // Time to look up a Slack ID, well within the webhook timeout
+
+	SharedSecrets  pkgsandbox.SecretReferences `split_words:"true"` // Shared with the controller, as namespace/name
+	AllowedSecrets pkgsandbox.SecretReferences `split_words:"true"` // Shared with the controller, as namespace/name
+
+	MaxSandboxesPerUser int           `split_words:"true" default:"0"` // Default no limit
+	MaxTotalLifetime    time.Duration `split_words:"true" default:"0"` // Summed over the sandboxes of a user, default no limit
+	GroupQuotas         GroupQuotas   `split_words:"true"`             // Quotas for members of groups, as group:maxSandboxes:maxTotalLifetime
+}
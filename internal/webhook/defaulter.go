@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultPath is the path on which the SandboxDefaulter is served.
+const DefaultPath = "/mutate-devops-stackstate-com-v1-sandbox"
+
+var invalidUserChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// SlackIdLookup finds the Slack member ID of a user by its email address.
+type SlackIdLookup interface {
+	LookupSlackId(ctx context.Context, email string) (string, error)
+}
+
+// +kubebuilder:webhook:path=/mutate-devops-stackstate-com-v1-sandbox,mutating=true,failurePolicy=fail,groups=devops.stackstate.com,resources=sandboxes,verbs=create,versions=v1,name=msandbox.kb.io
+
+// SandboxDefaulter fills in the user, Slack ID and expiration date of new Sandboxes.
+type SandboxDefaulter struct {
+	Config *Config
+	// Lookup is used to find the Slack ID of users that are not in Config.SlackIds, it is optional.
+	Lookup  SlackIdLookup
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (d *SandboxDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	sandbox := &devopsv1.Sandbox{}
+	if err := d.decoder.Decode(req, sandbox); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	d.setDefaults(ctx, sandbox, req.UserInfo)
+
+	marshaled, err := json.Marshal(sandbox)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (d *SandboxDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+func (d *SandboxDefaulter) setDefaults(ctx context.Context, sandbox *devopsv1.Sandbox, userInfo authenticationv1.UserInfo) {
+	log := ctrl.Log.WithName("webhook").WithValues("sandbox", sandbox.Name)
+
+	if sandbox.Spec.User == "" {
+		sandbox.Spec.User = userName(userInfo.Username)
+	}
+
+	if sandbox.Spec.SlackId == "" {
+		if slackId, ok := d.Config.SlackIds[sandbox.Spec.User]; ok {
+			sandbox.Spec.SlackId = slackId
+		} else if d.Lookup != nil && strings.Contains(userInfo.Username, "@") && sandbox.Spec.User == userName(userInfo.Username) {
+			// Only the Slack ID of the requester can be looked up, a Sandbox for someone else is admitted without one.
+			// The Sandbox is also admitted without a Slack ID when Slack is slow, rather than failing the request.
+			lookupCtx, cancel := context.WithTimeout(ctx, d.Config.SlackLookupTimeout)
+			slackId, err := d.Lookup.LookupSlackId(lookupCtx, userInfo.Username)
+			cancel()
+			if err != nil {
+				log.Error(err, "Could not look up Slack ID", "user", userInfo.Username)
+				slackId = ""
+			}
+			sandbox.Spec.SlackId = slackId
+		}
+	}
+
+	if sandbox.Spec.ExpirationDate == nil && !sandbox.Spec.ManualExpiry && d.Config.DefaultTtl > 0 {
+		expirationDate := metav1.NewTime(clock.Ctx(ctx).Now().Add(d.Config.DefaultTtl))
+		sandbox.Spec.ExpirationDate = &expirationDate
+	}
+}
+
+// userName derives the sandbox user from the name of the authenticated user, e.g. jdoe@stackstate.com becomes jdoe.
+// It returns an empty string for system users or if no valid user can be derived, leaving it to the validation to
+// reject the Sandbox.
+func userName(username string) string {
+	if strings.HasPrefix(username, "system:") {
+		return ""
+	}
+
+	name := strings.ToLower(strings.SplitN(username, "@", 2)[0])
+	name = strings.Trim(invalidUserChars.ReplaceAllString(name, "-"), "-")
+
+	if len(validation.IsDNS1123Label(name)) > 0 {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"gotest.tools/v3/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type mockLookup map[string]string
+
+func (m mockLookup) LookupSlackId(ctx context.Context, email string) (string, error) {
+	return m[email], nil
+}
+
+// slowLookup does not answer before the deadline of the lookup.
+type slowLookup struct{}
+
+func (slowLookup) LookupSlackId(ctx context.Context, email string) (string, error) {
+	<-ctx.Done()
+	return "U0LATE", ctx.Err()
+}
+
+func TestSetDefaults(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	var tests = map[string]struct {
+		username        string
+		spec            devopsv1.SandboxSpec
+		expectedUser    string
+		expectedSlackId string
+		hasExpiration   bool
+	}{
+		"Defaults from email":              {"jdoe@stackstate.com", devopsv1.SandboxSpec{}, "jdoe", "U0LOOKUP", true},
+		"Defaults from configured users":   {"jane", devopsv1.SandboxSpec{}, "jane", "U0CONFIG", true},
+		"Keeps given user and slack id":    {"jdoe@stackstate.com", devopsv1.SandboxSpec{User: "john", SlackId: "U0GIVEN"}, "john", "U0GIVEN", true},
+		"No expiration with manual expiry": {"jane", devopsv1.SandboxSpec{ManualExpiry: true}, "jane", "U0CONFIG", false},
+		"Looks up the given requester":     {"jdoe@stackstate.com", devopsv1.SandboxSpec{User: "jdoe"}, "jdoe", "U0LOOKUP", true},
+		"No lookup for another user":       {"jdoe@stackstate.com", devopsv1.SandboxSpec{User: "john"}, "john", "", true},
+		"Invalid user name":                {"system:serviceaccount:default:deployer", devopsv1.SandboxSpec{}, "", "", true},
+	}
+
+	defaulter := &SandboxDefaulter{
+		Config: &Config{
+			DefaultTtl:         7 * Day,
+			SlackIds:           map[string]string{"jane": "U0CONFIG"},
+			SlackLookupTimeout: time.Second,
+		},
+		Lookup: mockLookup{"jdoe@stackstate.com": "U0LOOKUP"},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{Spec: data.spec}
+			defaulter.setDefaults(ctx, sandbox, authenticationv1.UserInfo{Username: data.username})
+
+			assert.Equal(t, sandbox.Spec.User, data.expectedUser)
+			assert.Equal(t, sandbox.Spec.SlackId, data.expectedSlackId)
+			if data.hasExpiration {
+				assert.DeepEqual(t, sandbox.Spec.ExpirationDate, &v1.Time{Time: c.Now().Add(7 * Day)})
+			} else {
+				assert.Assert(t, sandbox.Spec.ExpirationDate == nil)
+			}
+		})
+	}
+}
+
+func TestSetDefaultsWithSlowSlackLookup(t *testing.T) {
+	defaulter := &SandboxDefaulter{
+		Config: &Config{SlackLookupTimeout: 10 * time.Millisecond},
+		Lookup: slowLookup{},
+	}
+
+	sandbox := &devopsv1.Sandbox{}
+	defaulter.setDefaults(context.Background(), sandbox, authenticationv1.UserInfo{Username: "jdoe@stackstate.com"})
+
+	assert.Equal(t, sandbox.Spec.User, "jdoe")
+	assert.Equal(t, sandbox.Spec.SlackId, "")
+}
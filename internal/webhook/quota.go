@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Quota limits the sandboxes of a single user, a zero value means no limit.
+type Quota struct {
+	MaxSandboxes     int
+	MaxTotalLifetime time.Duration
+}
+
+// GroupQuotas overrides the Quota for the members of groups. It can be read from the environment as comma separated
+// group:maxSandboxes:maxTotalLifetime triples, e.g. sre:10:2160h.
+type GroupQuotas map[string]Quota
+
+// Decode implements envconfig.Decoder
+func (g *GroupQuotas) Decode(value string) error {
+	quotas := GroupQuotas{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return fmt.Errorf("invalid quota %q, expected group:maxSandboxes:maxTotalLifetime", entry)
+		}
+
+		maxSandboxes, err := strconv.Atoi(parts[1])
+		if err != nil || maxSandboxes < 0 {
+			return fmt.Errorf("invalid quota %q, maxSandboxes must be a number", entry)
+		}
+
+		maxTotalLifetime, err := time.ParseDuration(parts[2])
+		if err != nil || maxTotalLifetime < 0 {
+			return fmt.Errorf("invalid quota %q, maxTotalLifetime must be a duration", entry)
+		}
+
+		quotas[parts[0]] = Quota{MaxSandboxes: maxSandboxes, MaxTotalLifetime: maxTotalLifetime}
+	}
+
+	*g = quotas
+	return nil
+}
+
+// quotaFor returns the quota of a user in the given groups. When several groups have an override, the most
+// permissive limit of each kind applies.
+func (c *Config) quotaFor(groups []string) Quota {
+	quota := Quota{MaxSandboxes: c.MaxSandboxesPerUser, MaxTotalLifetime: c.MaxTotalLifetime}
+
+	overridden := false
+	for _, group := range groups {
+		override, ok := c.GroupQuotas[group]
+		if !ok {
+			continue
+		}
+
+		if !overridden {
+			quota, overridden = override, true
+			continue
+		}
+
+		quota.MaxSandboxes = maxLimit(quota.MaxSandboxes, override.MaxSandboxes)
+		quota.MaxTotalLifetime = time.Duration(maxLimit(int(quota.MaxTotalLifetime), int(override.MaxTotalLifetime)))
+	}
+
+	return quota
+}
+
+// maxLimit returns the most permissive of two limits, where zero means no limit.
+func maxLimit(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// validateQuota checks that creating the Sandbox, or extending its lifetime, keeps its user within the quota. Quotas
+// are enforced per user, so a Sandbox can only be created for the requester itself when a quota applies. An update by
+// someone else than the owner, like an extension through the Slack buttons, is checked against the default quota, as
+// the groups of the owner are not known then.
+func (v *SandboxValidator) validateQuota(ctx context.Context, sandbox *devopsv1.Sandbox, old *devopsv1.Sandbox, userInfo authenticationv1.UserInfo) (field.ErrorList, error) {
+	isOwner := userName(userInfo.Username) == sandbox.Spec.User
+	groups := userInfo.Groups
+	if old != nil && !isOwner {
+		groups = nil
+	}
+
+	quota := v.Config.quotaFor(groups)
+	if v.Client == nil || (quota.MaxSandboxes == 0 && quota.MaxTotalLifetime == 0) {
+		return nil, nil
+	}
+
+	path := field.NewPath("spec", "user")
+	if old == nil && !isOwner {
+		return field.ErrorList{field.Forbidden(path, fmt.Sprintf("sandboxes can only be created for the requester, "+
+			"as quotas are enforced per user, expected %q", userName(userInfo.Username)))}, nil
+	}
+
+	// A Sandbox with manual expiry never expires, so it would not fit in any lifetime quota
+	if quota.MaxTotalLifetime > 0 && sandbox.Spec.ManualExpiry && (old == nil || !old.Spec.ManualExpiry) {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "manual_expiry"), fmt.Sprintf(
+			"sandboxes with manual expiry never expire, which exceeds the maximum total lifetime of %s", quota.MaxTotalLifetime))}, nil
+	}
+
+	// Only an extension of the lifetime can exceed the quota on update
+	now := clock.Ctx(ctx).Now()
+	if old != nil && (quota.MaxTotalLifetime == 0 || v.lifetime(sandbox, now, quota) <= v.lifetime(old, now, quota)) {
+		return nil, nil
+	}
+
+	sandboxes := &devopsv1.SandboxList{}
+	if err := v.Client.List(ctx, sandboxes); err != nil {
+		return nil, err
+	}
+
+	existing := []string{}
+	totalLifetime := v.lifetime(sandbox, now, quota)
+	for i := range sandboxes.Items {
+		sb := &sandboxes.Items[i]
+		if sb.Spec.User != sandbox.Spec.User || sb.Name == sandbox.Name || !sb.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		existing = append(existing, sb.Name)
+		totalLifetime += v.lifetime(sb, now, quota)
+	}
+	sort.Strings(existing)
+
+	errs := field.ErrorList{}
+	if old == nil && quota.MaxSandboxes > 0 && len(existing) >= quota.MaxSandboxes {
+		errs = append(errs, field.Forbidden(path, fmt.Sprintf("user %s already has %d of at most %d sandboxes: %s",
+			sandbox.Spec.User, len(existing), quota.MaxSandboxes, strings.Join(existing, ", "))))
+	}
+
+	if quota.MaxTotalLifetime > 0 && totalLifetime > quota.MaxTotalLifetime {
+		errs = append(errs, field.Forbidden(path, fmt.Sprintf("the total lifetime of the sandboxes of user %s would be %s, "+
+			"which exceeds the maximum of %s, shorten or delete one of: %s",
+			sandbox.Spec.User, totalLifetime, quota.MaxTotalLifetime, strings.Join(existing, ", "))))
+	}
+
+	return errs, nil
+}
+
+// lifetime returns the time from the creation of the Sandbox until its expiration date. Sandboxes without an
+// expiration date count with the default TTL. Sandboxes with manual expiry, that were created before it was limited,
+// never expire, so they count with the maximum lifetime, or with the whole quota if the lifetime is not limited.
+func (v *SandboxValidator) lifetime(sandbox *devopsv1.Sandbox, now time.Time, quota Quota) time.Duration {
+	if sandbox.Spec.ManualExpiry {
+		if v.Config.MaxLifetime > 0 {
+			return v.Config.MaxLifetime
+		}
+		return quota.MaxTotalLifetime
+	}
+
+	created := now
+	if !sandbox.CreationTimestamp.IsZero() {
+		created = sandbox.CreationTimestamp.Time
+	}
+
+	if sandbox.Spec.ExpirationDate == nil {
+		return v.Config.DefaultTtl
+	}
+
+	return sandbox.Spec.ExpirationDate.Sub(created)
+}
@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	"gotest.tools/v3/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateQuota(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, devopsv1.AddToScheme(scheme))
+
+	existing := func(name, user string, lifetime time.Duration) *devopsv1.Sandbox {
+		expirationDate := v1.NewTime(c.Now().Add(lifetime))
+		return &devopsv1.Sandbox{
+			ObjectMeta: v1.ObjectMeta{Name: name, CreationTimestamp: v1.NewTime(c.Now())},
+			Spec:       devopsv1.SandboxSpec{User: user, ExpirationDate: &expirationDate},
+		}
+	}
+
+	validator := &SandboxValidator{
+		Config: &Config{
+			DefaultTtl:          7 * Day,
+			MaxSandboxesPerUser: 2,
+			MaxTotalLifetime:    21 * Day,
+			GroupQuotas:         GroupQuotas{"sre": {MaxSandboxes: 5, MaxTotalLifetime: 0}},
+		},
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			existing("test-1", "jdoe", 7*Day),
+			existing("test-2", "jdoe", 7*Day),
+			existing("test-3", "asmith", 14*Day),
+			existing("test-4", "jane", 30*Day),
+		).Build(),
+	}
+
+	var tests = map[string]struct {
+		user      string
+		requester string
+		groups    []string
+		errors    []string
+	}{
+		"Within quota":                              {"asmith", "asmith@stackstate.com", nil, nil},
+		"Without sandboxes":                         {"bob", "bob@stackstate.com", nil, nil},
+		"Too many sandboxes":                        {"jdoe", "jdoe@stackstate.com", nil, []string{"user jdoe already has 2 of at most 2 sandboxes: test-1, test-2"}},
+		"Total lifetime exceeded":                   {"jane", "jane@stackstate.com", nil, []string{"the total lifetime of the sandboxes of user jane would be 888h0m0s"}},
+		"Group override":                            {"jdoe", "jdoe@stackstate.com", []string{"system:authenticated", "sre"}, nil},
+		"For another user":                          {"bob", "jdoe@stackstate.com", nil, []string{`sandboxes can only be created for the requester, as quotas are enforced per user, expected "jdoe"`}},
+		"For another user by a group without quota": {"bob", "system:serviceaccount:ci:deployer", []string{"ci"}, nil},
+	}
+
+	validator.Config.GroupQuotas["ci"] = Quota{}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "new"},
+				Spec:       devopsv1.SandboxSpec{User: data.user},
+			}
+
+			errs, err := validator.validateQuota(ctx, sandbox, nil, authenticationv1.UserInfo{Username: data.requester, Groups: data.groups})
+			assert.NilError(t, err)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateQuotaOnUpdate(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, devopsv1.AddToScheme(scheme))
+
+	existing := func(name string, lifetime time.Duration) *devopsv1.Sandbox {
+		expirationDate := v1.NewTime(c.Now().Add(lifetime))
+		return &devopsv1.Sandbox{
+			ObjectMeta: v1.ObjectMeta{Name: name, CreationTimestamp: v1.NewTime(c.Now())},
+			Spec:       devopsv1.SandboxSpec{User: "jdoe", ExpirationDate: &expirationDate},
+		}
+	}
+
+	validator := &SandboxValidator{
+		Config: &Config{
+			DefaultTtl:          7 * Day,
+			MaxSandboxesPerUser: 2,
+			MaxTotalLifetime:    21 * Day,
+			GroupQuotas:         GroupQuotas{"sre": {MaxSandboxes: 5, MaxTotalLifetime: 60 * Day}},
+		},
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			existing("test-1", 7*Day),
+			existing("test-2", 7*Day),
+			existing("test-3", 7*Day),
+		).Build(),
+	}
+
+	var tests = map[string]struct {
+		lifetime  time.Duration
+		requester string
+		groups    []string
+		errors    []string
+	}{
+		"Shortened":                   {5 * Day, "jdoe@stackstate.com", nil, nil},
+		"Extended within quota":       {7 * Day, "jdoe@stackstate.com", nil, nil},
+		"Extended beyond quota":       {8 * Day, "jdoe@stackstate.com", nil, []string{"the total lifetime of the sandboxes of user jdoe would be 528h0m0s"}},
+		"Extended with a group quota": {8 * Day, "jdoe@stackstate.com", []string{"sre"}, nil},
+		"Extended by someone else":    {8 * Day, "system:serviceaccount:sandbox-operator:default", []string{"sre"}, []string{"exceeds the maximum of 504h0m0s"}},
+	}
+
+	// The user already has more sandboxes than allowed, which is only checked on creation
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			old := existing("test-1", 6*Day)
+			sandbox := existing("test-1", data.lifetime)
+
+			errs, err := validator.validateQuota(ctx, sandbox, old, authenticationv1.UserInfo{Username: data.requester, Groups: data.groups})
+			assert.NilError(t, err)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateQuotaWithManualExpiry(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, devopsv1.AddToScheme(scheme))
+
+	// A Sandbox with manual expiry from before the lifetime was limited
+	kept := &devopsv1.Sandbox{
+		ObjectMeta: v1.ObjectMeta{Name: "kept", CreationTimestamp: v1.NewTime(c.Now())},
+		Spec:       devopsv1.SandboxSpec{User: "jdoe", ManualExpiry: true},
+	}
+
+	var tests = map[string]struct {
+		maxLifetime  time.Duration
+		lifetime     time.Duration
+		manualExpiry bool
+		errors       []string
+	}{
+		"Within quota":                           {10 * Day, 11 * Day, false, nil},
+		"Manual expiry counts as max lifetime":   {10 * Day, 12 * Day, false, []string{"the total lifetime of the sandboxes of user jdoe would be 528h0m0s"}},
+		"Manual expiry counts as the full quota": {0, 1 * Day, false, []string{"the total lifetime of the sandboxes of user jdoe would be 528h0m0s"}},
+		"New sandbox with manual expiry":         {10 * Day, 1 * Day, true, []string{"spec.manual_expiry: Forbidden"}},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator := &SandboxValidator{
+				Config: &Config{
+					MaxLifetime:      data.maxLifetime,
+					DefaultTtl:       7 * Day,
+					MaxTotalLifetime: 21 * Day,
+				},
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(kept.DeepCopy()).Build(),
+			}
+
+			expirationDate := v1.NewTime(c.Now().Add(data.lifetime))
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "new"},
+				Spec:       devopsv1.SandboxSpec{User: "jdoe", ExpirationDate: &expirationDate, ManualExpiry: data.manualExpiry},
+			}
+
+			errs, err := validator.validateQuota(ctx, sandbox, nil, authenticationv1.UserInfo{Username: "jdoe@stackstate.com"})
+			assert.NilError(t, err)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestQuotaFor(t *testing.T) {
+	config := &Config{
+		MaxSandboxesPerUser: 2,
+		MaxTotalLifetime:    14 * Day,
+		GroupQuotas: GroupQuotas{
+			"sre":     {MaxSandboxes: 5, MaxTotalLifetime: 30 * Day},
+			"interns": {MaxSandboxes: 1, MaxTotalLifetime: 7 * Day},
+			"admins":  {MaxSandboxes: 0, MaxTotalLifetime: 60 * Day},
+		},
+	}
+
+	var tests = map[string]struct {
+		groups   []string
+		expected Quota
+	}{
+		"No groups":           {nil, Quota{MaxSandboxes: 2, MaxTotalLifetime: 14 * Day}},
+		"Restrictive group":   {[]string{"interns"}, Quota{MaxSandboxes: 1, MaxTotalLifetime: 7 * Day}},
+		"Most permissive":     {[]string{"interns", "sre"}, Quota{MaxSandboxes: 5, MaxTotalLifetime: 30 * Day}},
+		"Unlimited sandboxes": {[]string{"sre", "admins"}, Quota{MaxSandboxes: 0, MaxTotalLifetime: 60 * Day}},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, config.quotaFor(data.groups), data.expected)
+		})
+	}
+}
+
+func TestDecodeGroupQuotas(t *testing.T) {
+	quotas := GroupQuotas{}
+	assert.NilError(t, quotas.Decode("sre:10:2160h, interns:1:0"))
+	assert.DeepEqual(t, quotas, GroupQuotas{
+		"sre":     {MaxSandboxes: 10, MaxTotalLifetime: 90 * Day},
+		"interns": {MaxSandboxes: 1, MaxTotalLifetime: 0},
+	})
+
+	assert.ErrorContains(t, quotas.Decode("sre:10"), "expected group:maxSandboxes:maxTotalLifetime")
+	assert.ErrorContains(t, quotas.Decode("sre:many:1h"), "maxSandboxes must be a number")
+	assert.ErrorContains(t, quotas.Decode("sre:1:forever"), "maxTotalLifetime must be a duration")
+}
@@ -0,0 +1,286 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ValidatePath is the path on which the SandboxValidator is served.
+const ValidatePath = "/validate-devops-stackstate-com-v1-sandbox"
+
+// slackIdPattern matches Slack member IDs, e.g. U0123ABCD.
+var slackIdPattern = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+
+// +kubebuilder:webhook:path=/validate-devops-stackstate-com-v1-sandbox,mutating=false,failurePolicy=fail,groups=devops.stackstate.com,resources=sandboxes,verbs=create;update,versions=v1,name=vsandbox.kb.io
+
+// SandboxValidator rejects Sandboxes that cannot be turned into a valid sandbox namespace, that have an expiration
+// date in the past or beyond the maximum lifetime, that never expire while the lifetime is limited, that request
+// secrets the operator does not allow, or that grant access to groups the requester is not a member of. Sandboxes are
+// also rejected when they are created or extended beyond the quota of their user.
+type SandboxValidator struct {
+	Config *Config
+	// Client is used to find the existing Sandboxes of a user, quotas are not enforced without it.
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+// Handle implements admission.Handler
+func (v *SandboxValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	sandbox := &devopsv1.Sandbox{}
+	if err := v.decoder.Decode(req, sandbox); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old *devopsv1.Sandbox
+	if req.Operation == admissionv1.Update {
+		old = &devopsv1.Sandbox{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	errs := v.validate(ctx, sandbox, old)
+	errs = append(errs, validateGroups(sandbox, old, req.UserInfo)...)
+	quotaErrs, err := v.validateQuota(ctx, sandbox, old, req.UserInfo)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	errs = append(errs, quotaErrs...)
+
+	if len(errs) > 0 {
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+
+	return admission.Allowed("")
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *SandboxValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// validate checks the Sandbox, old is the Sandbox before the update or nil if it is being created.
+func (v *SandboxValidator) validate(ctx context.Context, sandbox *devopsv1.Sandbox, old *devopsv1.Sandbox) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+
+	if sandbox.Spec.User == "" {
+		errs = append(errs, field.Required(spec.Child("user"), ""))
+	}
+
+	// Only validate the Slack ID when the Sandbox is created or the ID is changed, so that Sandboxes created before it
+	// was validated can still be updated, and released by the operator.
+	if old == nil || sandbox.Spec.SlackId != old.Spec.SlackId {
+		if sandbox.Spec.SlackId == "" {
+			errs = append(errs, field.Required(spec.Child("slack_id"), ""))
+		} else if !slackIdPattern.MatchString(sandbox.Spec.SlackId) {
+			errs = append(errs, field.Invalid(spec.Child("slack_id"), sandbox.Spec.SlackId, "must be a Slack member ID, e.g. U0123ABCD"))
+		}
+	}
+
+	name := pkgsandbox.SandboxName(sandbox)
+	nameErrors := validation.IsDNS1123Label(name)
+	for _, msg := range nameErrors {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), sandbox.Name,
+			fmt.Sprintf("generated namespace name %s is invalid: %s", name, msg)))
+	}
+
+	// The suffixed names are only checked when the base name is valid, to not repeat the same error for every suffix
+	if len(nameErrors) == 0 {
+		suffixes := map[string]bool{}
+		for i, suffix := range sandbox.Spec.Namespaces {
+			path := spec.Child("namespaces").Index(i)
+			if suffixes[suffix] {
+				errs = append(errs, field.Duplicate(path, suffix))
+			}
+			suffixes[suffix] = true
+
+			namespace := fmt.Sprintf("%s-%s", name, suffix)
+			for _, msg := range validation.IsDNS1123Label(namespace) {
+				errs = append(errs, field.Invalid(path, suffix, fmt.Sprintf("generated namespace name %s is invalid: %s", namespace, msg)))
+			}
+		}
+	}
+
+	// Namespaces that are removed from the Sandbox are deleted with all their contents, which has to be asked for
+	if old != nil && sandbox.Spec.User == old.Spec.User && sandbox.Annotations[devopsv1.PruneNamespacesAnnotation] != "true" {
+		names := pkgsandbox.NamespaceNames(sandbox)
+		for _, name := range pkgsandbox.NamespaceNames(old) {
+			if !containsString(names, name) {
+				errs = append(errs, field.Forbidden(spec.Child("namespaces"), fmt.Sprintf(
+					"namespace %s would be deleted, annotate the sandbox with %s=true to allow it", name, devopsv1.PruneNamespacesAnnotation)))
+			}
+		}
+	}
+
+	if sandbox.Spec.Uptime != nil {
+		if _, err := pkgsandbox.ParseUptime(sandbox.Spec.Uptime); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("uptime"), sandbox.Spec.Uptime, err.Error()))
+		}
+	}
+
+	if warnings := sandbox.Spec.Warnings; warnings != nil {
+		path := spec.Child("warnings")
+		if warnings.FirstWarning != nil && warnings.FirstWarning.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child("first_warning"), warnings.FirstWarning.Duration.String(), "must not be negative"))
+		}
+		if warnings.Interval != nil && warnings.Interval.Duration <= 0 {
+			errs = append(errs, field.Invalid(path.Child("interval"), warnings.Interval.Duration.String(), "must be positive"))
+		}
+	}
+
+	// Only validate the hooks when they are set or changed, so that existing Sandboxes can still be released by the
+	// operator when they are deleted.
+	if old == nil || !reflect.DeepEqual(sandbox.Spec.PreDeleteHooks, old.Spec.PreDeleteHooks) {
+		errs = append(errs, validateHooks(sandbox)...)
+	}
+
+	// Only validate the secrets when they are set or changed, so that existing Sandboxes can still be updated when a
+	// secret is no longer allowed.
+	if old == nil || !reflect.DeepEqual(sandbox.Spec.Secrets, old.Spec.Secrets) {
+		errs = append(errs, v.validateSecrets(sandbox)...)
+	}
+
+	if old != nil && sandbox.Spec.User != old.Spec.User {
+		errs = append(errs, field.Forbidden(spec.Child("user"), "field is immutable"))
+	}
+
+	// Manual expiry keeps the Sandbox beyond any expiration date, so it is not allowed when the lifetime is limited. It is
+	// only checked when it is set, so that existing Sandboxes can still be updated.
+	if sandbox.Spec.ManualExpiry && v.Config.MaxLifetime > 0 && (old == nil || !old.Spec.ManualExpiry) {
+		errs = append(errs, field.Forbidden(spec.Child("manual_expiry"),
+			fmt.Sprintf("sandboxes can live at most %s, set an expiration date instead", v.Config.MaxLifetime)))
+	}
+
+	// Only validate the expiration date when it is set or changed, so that existing Sandboxes can still be updated
+	// once they are overdue.
+	if sandbox.Spec.ExpirationDate != nil && (old == nil || !sandbox.Spec.ExpirationDate.Equal(old.Spec.ExpirationDate)) {
+		errs = append(errs, v.validateExpirationDate(ctx, sandbox)...)
+	}
+
+	return errs
+}
+
+// validateGroups only allows the requester to grant access to the sandbox to groups it is a member of itself. System
+// groups are never allowed, as every user is a member of e.g. system:authenticated. Groups that the Sandbox already
+// had are not checked again, so that updates by others, like the operator, are still allowed.
+func validateGroups(sandbox *devopsv1.Sandbox, old *devopsv1.Sandbox, userInfo authenticationv1.UserInfo) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, group := range sandbox.Spec.Groups {
+		if old != nil && containsString(old.Spec.Groups, group) {
+			continue
+		}
+
+		path := field.NewPath("spec", "groups").Index(i)
+		if strings.HasPrefix(group, "system:") {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("system group %s cannot be granted access", group)))
+		} else if !containsString(userInfo.Groups, group) {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("%s is not a member of group %s", userInfo.Username, group)))
+		}
+	}
+
+	return errs
+}
+
+// validateHooks checks that the pre-delete hooks have unique names that can be turned into the name of their Job.
+func validateHooks(sandbox *devopsv1.Sandbox) field.ErrorList {
+	errs := field.ErrorList{}
+
+	names := map[string]bool{}
+	for i, hook := range sandbox.Spec.PreDeleteHooks {
+		path := field.NewPath("spec", "pre_delete_hooks").Index(i).Child("name")
+		if hook.Name == "" {
+			errs = append(errs, field.Required(path, ""))
+			continue
+		}
+
+		if names[hook.Name] {
+			errs = append(errs, field.Duplicate(path, hook.Name))
+		}
+		names[hook.Name] = true
+
+		// The name of the Job is also used as a label on its pods, so it has to be a valid label
+		jobName := pkgsandbox.HookJobName(hook)
+		for _, msg := range validation.IsDNS1123Label(jobName) {
+			errs = append(errs, field.Invalid(path, hook.Name, fmt.Sprintf("generated job name %s is invalid: %s", jobName, msg)))
+		}
+	}
+
+	return errs
+}
+
+// validateSecrets only allows the secrets that the operator allows to be copied. The copies are named after their
+// source, so the names must not be the same as those of the shared secrets or of each other.
+func (v *SandboxValidator) validateSecrets(sandbox *devopsv1.Sandbox) field.ErrorList {
+	errs := field.ErrorList{}
+
+	names := map[string]bool{}
+	for _, ref := range v.Config.SharedSecrets {
+		names[ref.Name] = true
+	}
+
+	for i, ref := range sandbox.Spec.Secrets {
+		path := field.NewPath("spec", "secrets").Index(i)
+		if !pkgsandbox.ContainsSecretReference(v.Config.AllowedSecrets, ref) {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("secret %s/%s is not allowed to be copied", ref.Namespace, ref.Name)))
+			continue
+		}
+
+		if names[ref.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), ref.Name))
+		}
+		names[ref.Name] = true
+	}
+
+	return errs
+}
+
+func (v *SandboxValidator) validateExpirationDate(ctx context.Context, sandbox *devopsv1.Sandbox) field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("spec", "expiration_date")
+	now := clock.Ctx(ctx).Now()
+	expirationDate := sandbox.Spec.ExpirationDate.Time
+
+	if !expirationDate.After(now) {
+		errs = append(errs, field.Invalid(path, expirationDate.Format(time.RFC3339), "must be in the future"))
+	}
+
+	created := now
+	if !sandbox.CreationTimestamp.IsZero() {
+		created = sandbox.CreationTimestamp.Time
+	}
+
+	if v.Config.MaxLifetime > 0 && expirationDate.Sub(created) > v.Config.MaxLifetime {
+		errs = append(errs, field.Invalid(path, expirationDate.Format(time.RFC3339),
+			fmt.Sprintf("must be at most %s after the creation of the sandbox", v.Config.MaxLifetime)))
+	}
+
+	return errs
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,406 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"github.com/stackvista/sandbox-operator/internal/clock"
+	pkgsandbox "github.com/stackvista/sandbox-operator/pkg/sandbox"
+	"gotest.tools/v3/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var Day = 24 * time.Hour
+
+func TestValidate(t *testing.T) {
+	c := clk.NewMock()
+	ctx := clock.WithContext(context.Background(), c)
+
+	var tests = map[string]struct {
+		name       string
+		user       string
+		slackId    string
+		expiration *time.Duration
+		oldUser    string
+		errors     []string
+	}{
+		"Valid sandbox":                  {"test-1", "jdoe", "U0123ABCD", pDuration(7 * Day), "", nil},
+		"Valid sandbox without expiry":   {"test-1", "jdoe", "U0123ABCD", nil, "", nil},
+		"Missing user and slack id":      {"test-1", "", "", nil, "", []string{"spec.user: Required", "spec.slack_id: Required"}},
+		"Invalid slack id":               {"test-1", "jdoe", "jdoe", nil, "", []string{"spec.slack_id: Invalid"}},
+		"Invalid namespace name":         {"Test_1", "jdoe", "U0123ABCD", nil, "", []string{"metadata.name: Invalid"}},
+		"Too long namespace name":        {strings.Repeat("x", 60), "jdoe", "U0123ABCD", nil, "", []string{"metadata.name: Invalid"}},
+		"Expiration date in the past":    {"test-1", "jdoe", "U0123ABCD", pDuration(-1 * Day), "", []string{"must be in the future"}},
+		"Expiration beyond max lifetime": {"test-1", "jdoe", "U0123ABCD", pDuration(31 * Day), "", []string{"must be at most 720h0m0s"}},
+		"User is immutable":              {"test-1", "jdoe", "U0123ABCD", nil, "jane", []string{"spec.user: Forbidden"}},
+	}
+
+	validator := &SandboxValidator{
+		Config: &Config{
+			MaxLifetime: 30 * Day,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: data.name},
+				Spec: devopsv1.SandboxSpec{
+					User:    data.user,
+					SlackId: data.slackId,
+				},
+			}
+
+			if data.expiration != nil {
+				expirationDate := v1.NewTime(c.Now().Add(*data.expiration))
+				sandbox.Spec.ExpirationDate = &expirationDate
+			}
+
+			var old *devopsv1.Sandbox
+			if data.oldUser != "" {
+				old = sandbox.DeepCopy()
+				old.Spec.User = data.oldUser
+			}
+
+			errs := validator.validate(ctx, sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateSlackIdOnUpdate(t *testing.T) {
+	var tests = map[string]struct {
+		oldSlackId string
+		slackId    string
+		errors     []string
+	}{
+		"Unchanged invalid slack id":  {"UsT7DV5WGG", "UsT7DV5WGG", nil},
+		"Unchanged missing slack id":  {"", "", nil},
+		"Changed to valid slack id":   {"UsT7DV5WGG", "UST7DV5WGG", nil},
+		"Changed to invalid slack id": {"U0123ABCD", "jdoe", []string{"spec.slack_id: Invalid"}},
+		"Removed slack id":            {"U0123ABCD", "", []string{"spec.slack_id: Required"}},
+	}
+
+	validator := &SandboxValidator{Config: &Config{}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:    "jdoe",
+					SlackId: data.slackId,
+				},
+			}
+			old := sandbox.DeepCopy()
+			old.Spec.SlackId = data.oldSlackId
+
+			errs := validator.validate(context.Background(), sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateManualExpiry(t *testing.T) {
+	var tests = map[string]struct {
+		maxLifetime time.Duration
+		oldManual   *bool
+		errors      []string
+	}{
+		"Without max lifetime":               {0, nil, nil},
+		"With max lifetime":                  {30 * Day, nil, []string{"spec.manual_expiry: Forbidden: sandboxes can live at most 720h0m0s"}},
+		"Unchanged on update":                {30 * Day, pBool(true), nil},
+		"Set on update":                      {30 * Day, pBool(false), []string{"spec.manual_expiry: Forbidden"}},
+		"Set on update without max lifetime": {0, pBool(false), nil},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator := &SandboxValidator{Config: &Config{MaxLifetime: data.maxLifetime}}
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:         "jdoe",
+					SlackId:      "U0123ABCD",
+					ManualExpiry: true,
+				},
+			}
+
+			var old *devopsv1.Sandbox
+			if data.oldManual != nil {
+				old = sandbox.DeepCopy()
+				old.Spec.ManualExpiry = *data.oldManual
+			}
+
+			errs := validator.validate(context.Background(), sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func pBool(b bool) *bool {
+	return &b
+}
+
+func pDuration(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	var tests = map[string]struct {
+		namespaces []string
+		errors     []string
+	}{
+		"Single namespace":   {nil, nil},
+		"Valid suffixes":     {[]string{"app", "data"}, nil},
+		"Duplicate suffix":   {[]string{"app", "app"}, []string{"spec.namespaces[1]: Duplicate"}},
+		"Invalid suffix":     {[]string{"App"}, []string{"spec.namespaces[0]: Invalid"}},
+		"Too long namespace": {[]string{strings.Repeat("x", 50)}, []string{"spec.namespaces[0]: Invalid"}},
+	}
+
+	validator := &SandboxValidator{Config: &Config{}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:       "jdoe",
+					SlackId:    "U0123ABCD",
+					Namespaces: data.namespaces,
+				},
+			}
+
+			errs := validator.validate(context.Background(), sandbox, nil)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateNamespaceRemoval(t *testing.T) {
+	var tests = map[string]struct {
+		oldNamespaces []string
+		namespaces    []string
+		annotations   map[string]string
+		errors        []string
+	}{
+		"Namespace added":                {[]string{"app"}, []string{"app", "data"}, nil, nil},
+		"Namespace removed":              {[]string{"app", "data"}, []string{"app"}, nil, []string{"namespace sandbox-jdoe-test-1-data would be deleted"}},
+		"Namespace renamed":              {[]string{"app"}, []string{"application"}, nil, []string{"namespace sandbox-jdoe-test-1-app would be deleted"}},
+		"Single namespace split":         {nil, []string{"app"}, nil, []string{"namespace sandbox-jdoe-test-1 would be deleted"}},
+		"Namespace removed when allowed": {[]string{"app", "data"}, []string{"app"}, map[string]string{devopsv1.PruneNamespacesAnnotation: "true"}, nil},
+	}
+
+	validator := &SandboxValidator{Config: &Config{}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1", Annotations: data.annotations},
+				Spec: devopsv1.SandboxSpec{
+					User:       "jdoe",
+					SlackId:    "U0123ABCD",
+					Namespaces: data.namespaces,
+				},
+			}
+			old := sandbox.DeepCopy()
+			old.Spec.Namespaces = data.oldNamespaces
+
+			errs := validator.validate(context.Background(), sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	var tests = map[string]struct {
+		warnings *devopsv1.WarningPolicy
+		errors   []string
+	}{
+		"No warning policy": {nil, nil},
+		"Valid policy": {&devopsv1.WarningPolicy{
+			FirstWarning: &v1.Duration{Duration: 2 * Day},
+			Interval:     &v1.Duration{Duration: 12 * time.Hour},
+		}, nil},
+		"Muted":                  {&devopsv1.WarningPolicy{Mute: true}, nil},
+		"Negative first warning": {&devopsv1.WarningPolicy{FirstWarning: &v1.Duration{Duration: -time.Hour}}, []string{"spec.warnings.first_warning: Invalid"}},
+		"Zero interval":          {&devopsv1.WarningPolicy{Interval: &v1.Duration{}}, []string{"spec.warnings.interval: Invalid"}},
+	}
+
+	validator := &SandboxValidator{Config: &Config{}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:     "jdoe",
+					SlackId:  "U0123ABCD",
+					Warnings: data.warnings,
+				},
+			}
+
+			errs := validator.validate(context.Background(), sandbox, nil)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	tls := corev1.SecretReference{Namespace: "infra", Name: "tls"}
+
+	var tests = map[string]struct {
+		secrets    []corev1.SecretReference
+		oldSecrets []corev1.SecretReference
+		errors     []string
+	}{
+		"No secrets":                   {nil, nil, nil},
+		"Allowed secret":               {[]corev1.SecretReference{tls}, nil, nil},
+		"Secret that is not allowed":   {[]corev1.SecretReference{{Namespace: "kube-system", Name: "admin-token"}}, nil, []string{"spec.secrets[0]: Forbidden"}},
+		"Same name as a shared secret": {[]corev1.SecretReference{{Namespace: "team", Name: "registry"}}, nil, []string{"spec.secrets[0].name: Duplicate"}},
+		"Same name as another secret":  {[]corev1.SecretReference{tls, {Namespace: "team", Name: "tls"}}, nil, []string{"spec.secrets[1].name: Duplicate"}},
+		"Unchanged secrets on update":  {[]corev1.SecretReference{{Namespace: "team", Name: "old"}}, []corev1.SecretReference{{Namespace: "team", Name: "old"}}, nil},
+		"Changed secrets on update":    {[]corev1.SecretReference{{Namespace: "team", Name: "new"}}, []corev1.SecretReference{{Namespace: "team", Name: "old"}}, []string{"spec.secrets[0]: Forbidden"}},
+	}
+
+	validator := &SandboxValidator{Config: &Config{
+		SharedSecrets:  pkgsandbox.SecretReferences{{Namespace: "infra", Name: "registry"}},
+		AllowedSecrets: pkgsandbox.SecretReferences{{Namespace: "infra", Name: "tls"}, {Namespace: "team", Name: "tls"}, {Namespace: "team", Name: "registry"}},
+	}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:    "jdoe",
+					SlackId: "U0123ABCD",
+					Secrets: data.secrets,
+				},
+			}
+
+			var old *devopsv1.Sandbox
+			if data.oldSecrets != nil {
+				old = sandbox.DeepCopy()
+				old.Spec.Secrets = data.oldSecrets
+			}
+
+			errs := validator.validate(context.Background(), sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	userInfo := authenticationv1.UserInfo{Username: "jdoe@stackstate.com", Groups: []string{"devops", "system:authenticated"}}
+
+	var tests = map[string]struct {
+		groups    []string
+		oldGroups []string
+		errors    []string
+	}{
+		"No groups":                          {nil, nil, nil},
+		"Group of the requester":             {[]string{"devops"}, nil, nil},
+		"Group the requester is not in":      {[]string{"sre"}, nil, []string{"spec.groups[0]: Forbidden: jdoe@stackstate.com is not a member of group sre"}},
+		"System group of the requester":      {[]string{"system:authenticated"}, nil, []string{"spec.groups[0]: Forbidden: system group"}},
+		"Unchanged group on update":          {[]string{"sre"}, []string{"sre"}, nil},
+		"Added group on update":              {[]string{"sre", "admins"}, []string{"sre"}, []string{"spec.groups[1]: Forbidden"}},
+		"Added group of requester on update": {[]string{"sre", "devops"}, []string{"sre"}, nil},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec:       devopsv1.SandboxSpec{User: "jdoe", Groups: data.groups},
+			}
+
+			var old *devopsv1.Sandbox
+			if data.oldGroups != nil {
+				old = sandbox.DeepCopy()
+				old.Spec.Groups = data.oldGroups
+			}
+
+			errs := validateGroups(sandbox, old, userInfo)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	hook := func(name string) devopsv1.SandboxHook {
+		return devopsv1.SandboxHook{Name: name, Image: "busybox"}
+	}
+
+	var tests = map[string]struct {
+		hooks    []devopsv1.SandboxHook
+		oldHooks []devopsv1.SandboxHook
+		errors   []string
+	}{
+		"No hooks":                  {nil, nil, nil},
+		"Valid hooks":               {[]devopsv1.SandboxHook{hook("backup"), hook("notify")}, nil, nil},
+		"Missing name":              {[]devopsv1.SandboxHook{hook("")}, nil, []string{"spec.pre_delete_hooks[0].name: Required"}},
+		"Invalid name":              {[]devopsv1.SandboxHook{hook("Back_up")}, nil, []string{"spec.pre_delete_hooks[0].name: Invalid"}},
+		"Too long job name":         {[]devopsv1.SandboxHook{hook(strings.Repeat("x", 45))}, nil, []string{"generated job name sandbox-pre-delete-xxx"}},
+		"Duplicate name":            {[]devopsv1.SandboxHook{hook("backup"), hook("backup")}, nil, []string{"spec.pre_delete_hooks[1].name: Duplicate"}},
+		"Unchanged hooks on update": {[]devopsv1.SandboxHook{hook("Back_up")}, []devopsv1.SandboxHook{hook("Back_up")}, nil},
+		"Changed hooks on update":   {[]devopsv1.SandboxHook{hook("Back_up")}, []devopsv1.SandboxHook{hook("backup")}, []string{"spec.pre_delete_hooks[0].name: Invalid"}},
+	}
+
+	validator := &SandboxValidator{Config: &Config{}}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			sandbox := &devopsv1.Sandbox{
+				ObjectMeta: v1.ObjectMeta{Name: "test-1"},
+				Spec: devopsv1.SandboxSpec{
+					User:           "jdoe",
+					SlackId:        "U0123ABCD",
+					PreDeleteHooks: data.hooks,
+				},
+			}
+
+			var old *devopsv1.Sandbox
+			if data.oldHooks != nil {
+				old = sandbox.DeepCopy()
+				old.Spec.PreDeleteHooks = data.oldHooks
+			}
+
+			errs := validator.validate(context.Background(), sandbox, old)
+			assert.Equal(t, len(errs), len(data.errors), errs.ToAggregate())
+			for _, msg := range data.errors {
+				assert.ErrorContains(t, errs.ToAggregate(), msg)
+			}
+		})
+	}
+}
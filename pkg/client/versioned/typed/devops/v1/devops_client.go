@@ -11,6 +11,7 @@ import (
 type DevopsV1Interface interface {
 	RESTClient() rest.Interface
 	SandboxesGetter
+	SandboxTemplatesGetter
 }
 
 // DevopsV1Client is used to interact with features provided by the devops.stackstate.com group.
@@ -22,6 +23,10 @@ func (c *DevopsV1Client) Sandboxes() SandboxInterface {
 	return newSandboxes(c)
 }
 
+func (c *DevopsV1Client) SandboxTemplates() SandboxTemplateInterface {
+	return newSandboxTemplates(c)
+}
+
 // NewForConfig creates a new DevopsV1Client for the given config.
 func NewForConfig(c *rest.Config) (*DevopsV1Client, error) {
 	config := *c
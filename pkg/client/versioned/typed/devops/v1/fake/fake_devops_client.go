@@ -16,6 +16,10 @@ func (c *FakeDevopsV1) Sandboxes() v1.SandboxInterface {
 	return &FakeSandboxes{c}
 }
 
+func (c *FakeDevopsV1) SandboxTemplates() v1.SandboxTemplateInterface {
+	return &FakeSandboxTemplates{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDevopsV1) RESTClient() rest.Interface {
@@ -3,3 +3,5 @@
 package v1
 
 type SandboxExpansion interface{}
+
+type SandboxTemplateExpansion interface{}
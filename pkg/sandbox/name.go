@@ -19,3 +19,24 @@ func SandboxName(sandbox *devopsv1.Sandbox) string {
 	return name
 
 }
+
+// NamespaceNames returns the names of the namespaces of the Sandbox, which is the SandboxName followed by each of the
+// namespace suffixes, or only the SandboxName if no suffixes are given. The first name is the primary namespace.
+func NamespaceNames(sandbox *devopsv1.Sandbox) []string {
+	name := SandboxName(sandbox)
+	if len(sandbox.Spec.Namespaces) == 0 {
+		return []string{name}
+	}
+
+	names := []string{}
+	for _, suffix := range sandbox.Spec.Namespaces {
+		names = append(names, fmt.Sprintf("%s-%s", name, suffix))
+	}
+
+	return names
+}
+
+// HookJobName returns the name of the Job that runs the pre-delete hook.
+func HookJobName(hook devopsv1.SandboxHook) string {
+	return fmt.Sprintf("sandbox-pre-delete-%s", hook.Name)
+}
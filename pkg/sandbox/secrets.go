@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretReferences is a list of secrets that can be read from the environment as comma separated namespace/name pairs.
+// It is shared by the controller and the webhooks, so that both accept the same secrets.
+type SecretReferences []corev1.SecretReference
+
+// Decode implements envconfig.Decoder
+func (s *SecretReferences) Decode(value string) error {
+	refs := SecretReferences{}
+	for _, ref := range strings.Split(value, ",") {
+		if ref = strings.TrimSpace(ref); ref == "" {
+			continue
+		}
+
+		parts := strings.Split(ref, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid secret reference %q, expected namespace/name", ref)
+		}
+
+		refs = append(refs, corev1.SecretReference{Namespace: parts[0], Name: parts[1]})
+	}
+
+	*s = refs
+	return nil
+}
+
+// ContainsSecretReference returns whether the secret with the namespace and name of ref is in refs.
+func ContainsSecretReference(refs []corev1.SecretReference, ref corev1.SecretReference) bool {
+	for _, r := range refs {
+		if r.Namespace == ref.Namespace && r.Name == ref.Name {
+			return true
+		}
+	}
+
+	return false
+}
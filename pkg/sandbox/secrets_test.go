@@ -0,0 +1,37 @@
+package sandbox
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDecodeSecretReferences(t *testing.T) {
+	var tests = map[string]struct {
+		value    string
+		expected SecretReferences
+		isError  bool
+	}{
+		"Empty":                  {"", SecretReferences{}, false},
+		"Single secret":          {"infra/registry", SecretReferences{{Namespace: "infra", Name: "registry"}}, false},
+		"Multiple secrets":       {"infra/registry, infra/tls", SecretReferences{{Namespace: "infra", Name: "registry"}, {Namespace: "infra", Name: "tls"}}, false},
+		"Missing namespace":      {"registry", nil, true},
+		"Empty name":             {"infra/", nil, true},
+		"Too many path segments": {"infra/registry/x", nil, true},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			refs := SecretReferences{}
+			err := refs.Decode(data.value)
+			if data.isError {
+				assert.ErrorContains(t, err, "expected namespace/name")
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, []corev1.SecretReference(refs), []corev1.SecretReference(data.expected))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetCondition sets the condition of the given type in the status of the Sandbox.
+// The transition time is only changed when the status of the condition changes.
+func SetCondition(sandbox *devopsv1.Sandbox, conditionType string, status metav1.ConditionStatus, reason string, message string, now time.Time) {
+	meta.SetStatusCondition(&sandbox.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: sandbox.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// RemoveCondition removes the condition of the given type from the status of the Sandbox, if present.
+func RemoveCondition(sandbox *devopsv1.Sandbox, conditionType string) {
+	// meta.RemoveStatusCondition cannot handle an empty list of conditions
+	if meta.FindStatusCondition(sandbox.Status.Conditions, conditionType) != nil {
+		meta.RemoveStatusCondition(&sandbox.Status.Conditions, conditionType)
+	}
+}
+
+// UpdatePhase derives the phase of the Sandbox from its conditions.
+func UpdatePhase(sandbox *devopsv1.Sandbox) {
+	conditions := sandbox.Status.Conditions
+
+	switch {
+	case sandbox.DeletionTimestamp != nil:
+		sandbox.Status.Phase = devopsv1.SandboxTerminating
+	case !meta.IsStatusConditionTrue(conditions, devopsv1.ConditionNamespaceReady) ||
+		!meta.IsStatusConditionTrue(conditions, devopsv1.ConditionRBACReady):
+		sandbox.Status.Phase = devopsv1.SandboxPending
+	case meta.IsStatusConditionTrue(conditions, devopsv1.ConditionHibernated):
+		sandbox.Status.Phase = devopsv1.SandboxHibernated
+	case meta.IsStatusConditionTrue(conditions, devopsv1.ConditionOverdue):
+		sandbox.Status.Phase = devopsv1.SandboxOverdue
+	case meta.IsStatusConditionTrue(conditions, devopsv1.ConditionExpiring):
+		sandbox.Status.Phase = devopsv1.SandboxExpiring
+	default:
+		sandbox.Status.Phase = devopsv1.SandboxReady
+	}
+}
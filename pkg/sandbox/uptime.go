@@ -0,0 +1,141 @@
+package sandbox
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Uptime is a parsed UptimeSchedule
+type Uptime struct {
+	days     [7]bool
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+}
+
+// ParseUptime parses the UptimeSchedule of a Sandbox
+func ParseUptime(schedule *devopsv1.UptimeSchedule) (*Uptime, error) {
+	u := &Uptime{location: time.UTC}
+
+	if err := u.parseDays(schedule.Days); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if u.start, err = parseTimeOfDay(schedule.Start); err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	if u.end, err = parseTimeOfDay(schedule.End); err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if u.start == u.end {
+		return nil, fmt.Errorf("start and end must differ")
+	}
+
+	if schedule.TimeZone != "" {
+		if u.location, err = time.LoadLocation(schedule.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+	}
+
+	return u, nil
+}
+
+// parseDays parses a comma-separated list of day names and ranges of day names, e.g. Mon-Fri,Sun. Ranges can wrap
+// around the end of the week, e.g. Fri-Mon. An empty list means every day.
+func (u *Uptime) parseDays(days string) error {
+	if strings.TrimSpace(days) == "" || days == "*" {
+		for i := range u.days {
+			u.days[i] = true
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(days, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return fmt.Errorf("invalid day %q", bounds[0])
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+				return fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+
+		for d := first; ; d = (d + 1) % 7 {
+			u.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTimeOfDay parses a time of day in the form HH:MM
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day like 07:00", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsUp returns whether t falls within an uptime window. A window that ends before it starts ends on the next day.
+func (u *Uptime) IsUp(t time.Time) bool {
+	t = t.In(u.location)
+	timeOfDay := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	if u.start < u.end {
+		return u.days[today] && timeOfDay >= u.start && timeOfDay < u.end
+	}
+
+	return (u.days[today] && timeOfDay >= u.start) || (u.days[yesterday] && timeOfDay < u.end)
+}
+
+// NextTransition returns the first moment after t at which the sandbox goes up or down, or the zero time if it never
+// changes.
+func (u *Uptime) NextTransition(t time.Time) time.Time {
+	up := u.IsUp(t)
+	local := t.In(u.location)
+
+	// Within 8 days every start and end of a window has passed at least once
+	for day := 0; day <= 8; day++ {
+		date := local.AddDate(0, 0, day)
+		boundaries := []time.Duration{u.start, u.end}
+		if u.end < u.start {
+			boundaries = []time.Duration{u.end, u.start}
+		}
+
+		for _, offset := range boundaries {
+			// The boundary is a wall clock time, which is not always a fixed offset from midnight due to DST
+			boundary := time.Date(date.Year(), date.Month(), date.Day(), 0, int(offset/time.Minute), 0, 0, u.location)
+			if boundary.After(t) && u.IsUp(boundary) != up {
+				return boundary
+			}
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,71 @@
+package sandbox
+
+import (
+	"testing"
+	"time"
+
+	devopsv1 "github.com/stackvista/sandbox-operator/apis/devops/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestUptime(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	assert.NilError(t, err)
+
+	weekdays := &devopsv1.UptimeSchedule{Days: "Mon-Fri", Start: "07:00", End: "19:00", TimeZone: "Europe/Amsterdam"}
+	nights := &devopsv1.UptimeSchedule{Days: "Fri-Sun", Start: "22:00", End: "02:00"}
+
+	var tests = map[string]struct {
+		schedule *devopsv1.UptimeSchedule
+		now      time.Time
+		up       bool
+		next     time.Time
+	}{
+		// 2021-03-01 is a Monday
+		"Before the window":         {weekdays, time.Date(2021, 3, 1, 6, 0, 0, 0, amsterdam), false, time.Date(2021, 3, 1, 7, 0, 0, 0, amsterdam)},
+		"Start of the window":       {weekdays, time.Date(2021, 3, 1, 7, 0, 0, 0, amsterdam), true, time.Date(2021, 3, 1, 19, 0, 0, 0, amsterdam)},
+		"Within the window":         {weekdays, time.Date(2021, 3, 1, 12, 0, 0, 0, amsterdam), true, time.Date(2021, 3, 1, 19, 0, 0, 0, amsterdam)},
+		"End of the window":         {weekdays, time.Date(2021, 3, 1, 19, 0, 0, 0, amsterdam), false, time.Date(2021, 3, 2, 7, 0, 0, 0, amsterdam)},
+		"In another time zone":      {weekdays, time.Date(2021, 3, 1, 6, 30, 0, 0, time.UTC), true, time.Date(2021, 3, 1, 19, 0, 0, 0, amsterdam)},
+		"Friday evening":            {weekdays, time.Date(2021, 3, 5, 20, 0, 0, 0, amsterdam), false, time.Date(2021, 3, 8, 7, 0, 0, 0, amsterdam)},
+		"Across a DST change":       {weekdays, time.Date(2021, 3, 26, 20, 0, 0, 0, amsterdam), false, time.Date(2021, 3, 29, 7, 0, 0, 0, amsterdam)},
+		"Overnight window":          {nights, time.Date(2021, 3, 5, 23, 0, 0, 0, time.UTC), true, time.Date(2021, 3, 6, 2, 0, 0, 0, time.UTC)},
+		"Overnight into Monday":     {nights, time.Date(2021, 3, 8, 1, 0, 0, 0, time.UTC), true, time.Date(2021, 3, 8, 2, 0, 0, 0, time.UTC)},
+		"Outside overnight windows": {nights, time.Date(2021, 3, 8, 3, 0, 0, 0, time.UTC), false, time.Date(2021, 3, 12, 22, 0, 0, 0, time.UTC)},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			uptime, err := ParseUptime(data.schedule)
+			assert.NilError(t, err)
+
+			assert.Equal(t, uptime.IsUp(data.now), data.up)
+			assert.Assert(t, uptime.NextTransition(data.now).Equal(data.next), uptime.NextTransition(data.now))
+		})
+	}
+}
+
+func TestParseUptime(t *testing.T) {
+	var tests = map[string]struct {
+		schedule devopsv1.UptimeSchedule
+		err      string
+	}{
+		"Every day":             {devopsv1.UptimeSchedule{Start: "07:00", End: "19:00"}, ""},
+		"List and wrapped days": {devopsv1.UptimeSchedule{Days: "mon,Wed,Fri-Sun", Start: "07:00", End: "19:00"}, ""},
+		"Invalid day":           {devopsv1.UptimeSchedule{Days: "Monday", Start: "07:00", End: "19:00"}, `invalid day "Monday"`},
+		"Invalid time":          {devopsv1.UptimeSchedule{Start: "7am", End: "19:00"}, "invalid start"},
+		"Empty window":          {devopsv1.UptimeSchedule{Start: "07:00", End: "07:00"}, "start and end must differ"},
+		"Invalid time zone":     {devopsv1.UptimeSchedule{Start: "07:00", End: "19:00", TimeZone: "Mars/Olympus"}, "invalid time zone"},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseUptime(&data.schedule)
+			if data.err == "" {
+				assert.NilError(t, err)
+			} else {
+				assert.ErrorContains(t, err, data.err)
+			}
+		})
+	}
+}